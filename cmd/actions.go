@@ -23,6 +23,7 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -31,7 +32,7 @@ import (
 	"github.com/shurcooL/graphql"
 	"github.com/spf13/cobra"
 	"github.com/stoe/gh-report/internal/utils"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -42,10 +43,26 @@ var (
 			`Report on GitHub Actions, requires %[1]srepo%[1]s scope`,
 			"`",
 		),
+		Args: cobra.NoArgs,
 		RunE: GetActionsReport,
 	}
 
-	exclude = false
+	exclude     = false
+	sarifDir    string
+	view        string
+	usesPattern string
+	summary     bool
+	top         int
+	graphFormat string
+	graphOutput string
+
+	includeArchived = false
+	includeForks    = false
+
+	workflowsRef string
+	allBranches  string
+
+	actionsViews = []string{"workflows", "runners", "secrets", "triggers", "schedules", "deprecations", "lint"}
 
 	ActionUsesQuery struct {
 		RepositoryOwner struct {
@@ -70,39 +87,57 @@ var (
 
 	mdActionsTemplate = `# GitHub Actions Report
 
-| Owner | Repo | Workflow | Uses | Permissions |
-| ----- | ---- | -------- | ---- | ----------- |
-{{ range . }}{{ $owner := .Owner }}{{ $repo := .Repo }}{{ range .Workflows }}| {{ $owner }} | {{ $repo }} | [{{ .Path }}]({{ .URL }}) | {{ range $i, $v := .Uses }}{{ if $i }}<br/>{{ end }}[{{ $v.Action }}]({{ $v.URL }}) {{ if $v.Version }}@ ` + "`" + `{{ printf "%.7s" $v.Version }}` + "`" + `{{ end }}{{ end }} | {{ range $i, $v := .Permissions }}{{if $i }}<br/>{{ end }} ` + "`" + `{{ $v }}` + "`" + `{{ end }} |
+| Owner | Repo | Branch | Visibility | Is Archived | Is Fork | Workflow | Uses | Permissions | Risks | Risk Score | Findings |
+| ----- | ---- | ------ | ---------- | ----------- | ------- | -------- | ---- | ----------- | ----- | ---------: | -------- |
+{{ range . }}{{ $owner := .Owner }}{{ $repo := .Repo }}{{ $branch := .Branch }}{{ $visibility := .Visibility }}{{ $archived := .Archived }}{{ $fork := .Fork }}{{ range .Workflows }}| {{ $owner }} | {{ $repo }} | {{ $branch }} | {{ $visibility }} | {{ $archived }} | {{ $fork }} | [{{ .Path }}]({{ .URL }}){{ if .Error }}<br/>:warning: {{ .Error }}{{ end }} | {{ range $i, $v := .Uses }}{{ if $i }}<br/>{{ end }}[{{ $v.Action }}]({{ $v.URL }}) {{ if $v.Version }}@ ` + "`" + `{{ printf "%.7s" $v.Version }}` + "`" + `{{ end }}{{ end }} | {{ range $i, $v := .Permissions }}{{if $i }}<br/>{{ end }} ` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ range $i, $v := .Risks }}{{ if $i }}<br/>{{ end }}` + "`" + `{{ $v.Rule }}` + "`" + ` {{ $v.Message }}{{ end }} | {{ .RiskScore }} | {{ $url := .URL }}{{ range $i, $v := .Findings }}{{ if $i }}<br/>{{ end }}[` + "`" + `{{ $v.Rule }}` + "`" + `]({{ $url }}#L{{ $v.Line }}) {{ $v.Message }}{{ end }} |
 {{ end }}{{ end }}
+## Repository Risk
+
+| Owner | Repo | Branch | Risk Score |
+| ----- | ---- | ------ | ---------: |
+{{ range . }}| {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .RiskScore }} |
+{{ end }}
 `
 )
 
 type (
 	ActionUsesRepository struct {
-		Name          string
-		NameWithOwner string
-		Owner         Organization
-		IsArchived    bool
-		IsFork        bool
-		Object        struct {
-			Tree struct {
-				Entries []struct {
-					Path   string
-					Name   string
-					Object struct {
-						Blob struct {
-							Text           string
-							AbbreviatedOid string
-							ByteSize       int
-							IsBinary       bool
-							IsTruncated    bool
-						} `graphql:"... on Blob"`
-					}
-					Extension string
-					Type      string
-				}
-			} `graphql:"... on Tree"`
-		} `graphql:"object(expression: $ref)"`
+		Name             string
+		NameWithOwner    string
+		Owner            Organization
+		Visibility       string
+		IsArchived       bool
+		IsFork           bool
+		DefaultBranchRef struct {
+			Name string
+		}
+		// the HEAD tree is not needed when the branches are enumerated
+		Object struct {
+			Tree WorkflowTree `graphql:"... on Tree"`
+		} `graphql:"object(expression: $ref) @skip(if: $allBranches)"`
+	}
+
+	WorkflowTree struct {
+		Entries []WorkflowEntry
+	}
+
+	WorkflowEntry struct {
+		Path   string
+		Name   string
+		Object struct {
+			Blob WorkflowBlob `graphql:"... on Blob"`
+		}
+		Extension string
+		Type      string
+	}
+
+	WorkflowBlob struct {
+		Text           string
+		Oid            string
+		AbbreviatedOid string
+		ByteSize       int
+		IsBinary       bool
+		IsTruncated    bool
 	}
 
 	WorkflowUses struct {
@@ -114,16 +149,32 @@ type (
 	}
 
 	ActionUsesReport struct {
-		Owner     string           `json:"owner"`
-		Repo      string           `json:"repo"`
-		Workflows []ActionWorkflow `json:"workflows"`
+		Owner      string           `json:"owner"`
+		Repo       string           `json:"repo"`
+		Branch     string           `json:"branch"`
+		Visibility string           `json:"visibility"`
+		Archived   bool             `json:"is_archived"`
+		Fork       bool             `json:"is_fork"`
+		RiskScore  int              `json:"risk_score"`
+		Workflows  []ActionWorkflow `json:"workflows"`
 	}
 
 	ActionWorkflow struct {
-		Path        string       `json:"path"`
-		URL         string       `json:"url"`
-		Uses        []ActionUses `json:"uses"`
-		Permissions []string     `json:"permissions"`
+		Path        string             `json:"path"`
+		URL         string             `json:"url"`
+		Error       string             `json:"error,omitempty"`
+		Uses        []ActionUses       `json:"uses"`
+		Permissions []string           `json:"permissions"`
+		RiskScore   int                `json:"risk_score"`
+		Risks       []WorkflowRisk     `json:"risks,omitempty"`
+		Findings    []WorkflowFinding  `json:"findings,omitempty"`
+		Runners     []WorkflowRunner   `json:"runners,omitempty"`
+		Secrets     []WorkflowSecrets  `json:"secrets,omitempty"`
+		Triggers    []WorkflowTrigger  `json:"triggers,omitempty"`
+		Schedules   []WorkflowSchedule `json:"schedules,omitempty"`
+		Commands    []WorkflowCommand  `json:"deprecated_commands,omitempty"`
+		Lint        WorkflowLint       `json:"lint"`
+		UsesSteps   []WorkflowUsesStep `json:"-"`
 	}
 
 	ActionUses struct {
@@ -146,6 +197,41 @@ func init() {
 	RootCmd.AddCommand(ActionsCmd)
 
 	ActionsCmd.Flags().BoolVar(&exclude, "exclude", false, "Exclude Github Actions authored by GitHub")
+	ActionsCmd.Flags().StringVar(&sarifDir, "sarif", "", "Path to directory, to save one SARIF file per repository to")
+	ActionsCmd.Flags().StringVar(
+		&view, "view", "workflows",
+		fmt.Sprintf("Report view, one of: %s", strings.Join(actionsViews, ", ")),
+	)
+	ActionsCmd.Flags().StringVar(
+		&usesPattern, "uses", "",
+		"Find uses of actions matching a glob on owner/repo, with an optional @ref glob or version constraint (e.g. tj-actions/changed-files@<v41)",
+	)
+
+	ActionsCmd.Flags().BoolVar(&summary, "summary", false, "Summarize the usage of every distinct action")
+	ActionsCmd.Flags().IntVar(&top, "top", 0, "Limit the summary to the top N most used actions (default: all)")
+
+	ActionsCmd.Flags().StringVar(
+		&graphFormat, "graph", "",
+		fmt.Sprintf("Export the dependency graph of actions and reusable workflows, one of: %s", strings.Join(graphFormats, ", ")),
+	)
+	ActionsCmd.Flags().StringVar(&graphOutput, "graph-output", "", "Path to file, to save the dependency graph in the --graph format to")
+
+	ActionsCmd.Flags().BoolVar(&includeArchived, "include-archived", false, "Include archived repositories")
+	ActionsCmd.Flags().BoolVar(&includeForks, "include-forks", false, "Include forked repositories")
+	ActionsCmd.Flags().BoolVar(&internal, "internal", false, "Show internal repositories only")
+	ActionsCmd.Flags().BoolVar(&private, "private", false, "Show private repositories only")
+	ActionsCmd.Flags().BoolVar(&public, "public", false, "Show public repositories only")
+
+	ActionsCmd.Flags().StringVar(&workflowsRef, "ref", "", "Branch or tag to analyze the workflows of (default: the default branch)")
+	ActionsCmd.Flags().StringVar(
+		&allBranches, "all-branches", "",
+		"Analyze the workflows of all branches, or of the branches matching a glob (e.g. --all-branches=release/*)",
+	)
+	ActionsCmd.Flags().Lookup("all-branches").NoOptDefVal = "*"
+
+	ActionsCmd.MarkFlagsMutuallyExclusive("view", "uses", "summary", "graph")
+	ActionsCmd.MarkFlagsMutuallyExclusive("ref", "all-branches")
+	ActionsCmd.MarkFlagsMutuallyExclusive("internal", "private", "public")
 }
 
 // GetActionsReport returns a report on GitHub Actions
@@ -154,6 +240,18 @@ func GetActionsReport(cmd *cobra.Command, args []string) (err error) {
 		return fmt.Errorf("Repository not (yet) supported for this report")
 	}
 
+	if !slices.Contains(actionsViews, view) {
+		return fmt.Errorf("unknown view %q, must be one of: %s", view, strings.Join(actionsViews, ", "))
+	}
+
+	if graphOutput != "" && graphFormat == "" {
+		return fmt.Errorf("--graph-output requires --graph")
+	}
+
+	if graphFormat != "" && !slices.Contains(graphFormats, graphFormat) {
+		return fmt.Errorf("unknown graph format %q, must be one of: %s", graphFormat, strings.Join(graphFormats, ", "))
+	}
+
 	sp.Start()
 
 	if enterprise != "" {
@@ -180,14 +278,23 @@ func GetActionsReport(cmd *cobra.Command, args []string) (err error) {
 
 	var res = []ActionUsesReport{}
 
+	ref := "HEAD"
+	if workflowsRef != "" {
+		ref = workflowsRef
+	}
+
 	for _, o := range organizations {
 		owner = o.Login
 		variables := map[string]interface{}{
-			"owner": graphql.String(owner),
-			"page":  (*graphql.String)(nil),
-			"ref":   graphql.String("HEAD:.github/workflows"),
+			"owner":       graphql.String(owner),
+			"page":        (*graphql.String)(nil),
+			"ref":         graphql.String(fmt.Sprintf("%s:.github/workflows", ref)),
+			"allBranches": graphql.Boolean(allBranches != ""),
 		}
 
+		// only analyze the repositories of the current organization
+		aur = []ActionUsesRepository{}
+
 		var i = 1
 		for {
 			sp.Suffix = fmt.Sprintf(
@@ -211,167 +318,299 @@ func GetActionsReport(cmd *cobra.Command, args []string) (err error) {
 		}
 
 		for _, r := range aur {
-			// skip if repo is archived or fork
-			if r.IsArchived || r.IsFork {
+			// skip if repo is archived or fork, or does not match the visibility filter
+			if skipRepository(r) {
 				continue
 			}
 
-			// skip if repo has no workflows
-			if len(r.Object.Tree.Entries) == 0 {
-				continue
-			}
-
-			var wfs = []ActionWorkflow{}
-			for _, e := range r.Object.Tree.Entries {
-				// skip if not a yml|yaml file
-				if _, ok := ce[e.Extension]; !ok {
-					continue
-				}
-
-				text := e.Object.Blob.Text
-
-				// get Action uses
-				var wu WorkflowUses
-				if err := yaml.Unmarshal([]byte(text), &wu); err != nil && !silent {
-					fmt.Println(
-						utils.Red(
-							fmt.Sprintf(
-								"\nerror: parsing https://%s/%s/blob/HEAD/%s",
-								hostname,
-								r.NameWithOwner, e.Path,
-							),
-						),
-					)
+			// analyze the workflows of every matching branch
+			if allBranches != "" {
+				branches, err := fetchWorkflowBranches(r, allBranches)
+				if err != nil {
+					sp.Stop()
+					return err
 				}
 
-				var uses []ActionUses
-				for _, job := range wu.Jobs {
-					for _, step := range job.Steps {
-						if step.Uses != "" && excludeGitHubAuthored(step.Uses) {
-							a := strings.Split(step.Uses, "@")
-
-							var an string
-							var av string
-							var url string
-
-							an = a[0]
-							if len(a) == 2 {
-								av = a[1]
-								url = fmt.Sprintf(
-									"https://%s/%s/tree/%s",
-									hostname,
-									an,
-									av,
-								)
-							} else {
-								url = fmt.Sprintf(
-									"https://%s/%s/tree/HEAD",
-									hostname,
-									an,
-								)
-							}
-
-							if strings.Contains(url, "./") {
-								url = fmt.Sprintf(
-									"https://%s/%s/%s/tree/HEAD/%s",
-									hostname,
-									r.Owner.Login,
-									r.Name,
-									strings.ReplaceAll(an, "./", ""),
-								)
-							}
-
-							uses = append(uses, ActionUses{
-								Action:  an,
-								Version: av,
-								URL:     url,
-							})
-						}
+				for _, b := range branches {
+					// skip if branch has no workflows
+					if b.Target.Commit.File == nil || len(b.Target.Commit.File.Object.Tree.Entries) == 0 {
+						continue
 					}
-				}
 
-				// get Action permissions
-				var wp ActionPermissions
-				if err := yaml.Unmarshal([]byte(text), &wp); err != nil && !silent {
-					fmt.Println(
-						utils.Red(
-							fmt.Sprintf(
-								"\nerror: parsing https://%s/%s/blob/HEAD/%s",
-								hostname,
-								r.NameWithOwner, e.Path,
-							),
-						),
-					)
+					res = append(res, workflowsReport(r, b.Name, b.Target.Commit.File.Object.Tree.Entries))
 				}
 
-				var permissions []string
-				// if permissions are defined at the workflow level
-				if wp.Permissions != nil {
-					permissions = append(permissions, getPermissions(wp.Permissions)...)
-				}
+				continue
+			}
 
-				// if permissions are defined at the job level
-				for _, job := range wp.Jobs {
-					permissions = append(permissions, getPermissions(job.Permissions)...)
-				}
+			// skip if repo has no workflows
+			if len(r.Object.Tree.Entries) == 0 {
+				continue
+			}
 
-				// put it all together
-				wfs = append(wfs, ActionWorkflow{
-					Path: e.Path,
-					URL: fmt.Sprintf(
-						"https://%s/%s/%s/blob/HEAD/%s",
-						hostname,
-						r.Owner.Login,
-						r.Name,
-						e.Path,
-					),
-					Uses:        uniqueUses(uses),
-					Permissions: uniquePermissions(permissions),
-				})
+			branch := r.DefaultBranchRef.Name
+			if workflowsRef != "" {
+				branch = workflowsRef
 			}
 
-			res = append(res, ActionUsesReport{
-				Owner:     r.Owner.Login,
-				Repo:      r.Name,
-				Workflows: wfs,
-			})
+			res = append(res, workflowsReport(r, branch, r.Object.Tree.Entries))
 		}
 
 		// sleep for 1 second to avoid rate limiting
 		time.Sleep(1 * time.Second)
 	}
 
+	// fetch the runtime of every action used, for the deprecations view
+	if view == "deprecations" {
+		fetchActionRuntimes(res)
+	}
+
 	sp.Stop()
 
-	var td = pterm.TableData{
-		{"owner", "repo", "workflow_path", "uses", "permissions"},
+	switch {
+	case usesPattern != "":
+		err = saveUsesView(res)
+	case summary:
+		err = saveSummaryView(res)
+	case graphFormat != "":
+		err = saveGraphView(res)
+	case view == "runners":
+		err = saveRunnersView(res)
+	case view == "secrets":
+		err = saveSecretsView(res)
+	case view == "triggers":
+		err = saveTriggersView(res)
+	case view == "schedules":
+		err = saveSchedulesView(res)
+	case view == "deprecations":
+		err = saveDeprecationsView(res)
+	case view == "lint":
+		err = saveLintView(res)
+	default:
+		err = saveWorkflowsView(res)
 	}
 
-	// start CSV file
-	if csvPath != "" {
-		actionsReport, err = utils.NewCSVReport(csvPath)
+	if err != nil {
+		return err
+	}
+
+	if sarifDir != "" {
+		for _, r := range res {
+			if err = utils.SaveSARIFReport(sarifPath(sarifDir, r), buildSarifLog(r)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return err
+}
 
+// workflowsReport analyzes the workflow files of a repository at branch
+func workflowsReport(r ActionUsesRepository, branch string, entries []WorkflowEntry) ActionUsesReport {
+	var wfs = []ActionWorkflow{}
+	for _, e := range entries {
+		// skip if not a yml|yaml file
+		if _, ok := ce[e.Extension]; !ok {
+			continue
+		}
+
+		// record workflows that cannot be loaded, and report them as empty workflows
+		var wfError string
+
+		text, root, err := loadWorkflow(r.NameWithOwner, e.Object.Blob)
 		if err != nil {
-			return err
+			wfError = err.Error()
+			root = &yaml.Node{Kind: yaml.MappingNode}
+		}
+
+		// get Action uses
+		var wu WorkflowUses
+		root.Decode(&wu)
+
+		var uses []ActionUses
+		for _, job := range wu.Jobs {
+			for _, step := range job.Steps {
+				if step.Uses != "" && excludeGitHubAuthored(step.Uses) {
+					a := strings.Split(step.Uses, "@")
+
+					var an string
+					var av string
+					var url string
+
+					an = a[0]
+					if len(a) == 2 {
+						av = a[1]
+						url = fmt.Sprintf(
+							"https://%s/%s/tree/%s",
+							hostname,
+							an,
+							av,
+						)
+					} else {
+						url = fmt.Sprintf(
+							"https://%s/%s/tree/HEAD",
+							hostname,
+							an,
+						)
+					}
+
+					if strings.Contains(url, "./") {
+						url = fmt.Sprintf(
+							"https://%s/%s/%s/tree/%s/%s",
+							hostname,
+							r.Owner.Login,
+							r.Name,
+							branch,
+							strings.ReplaceAll(an, "./", ""),
+						)
+					}
+
+					uses = append(uses, ActionUses{
+						Action:  an,
+						Version: av,
+						URL:     url,
+					})
+				}
+			}
 		}
 
-		actionsReport.SetHeader([]string{"owner", "repo", "workflow_path", "uses", "permissions"})
+		// get Action permissions
+		var wp ActionPermissions
+		root.Decode(&wp)
+
+		var permissions []string
+		// if permissions are defined at the workflow level
+		if wp.Permissions != nil {
+			permissions = append(permissions, getPermissions(wp.Permissions)...)
+		}
+
+		// if permissions are defined at the job level
+		for _, job := range wp.Jobs {
+			permissions = append(permissions, getPermissions(job.Permissions)...)
+		}
+
+		// evaluate risky permissions
+		risks := analyzePermissions(root)
+
+		// scan for dangerous triggers and script injections
+		findings := scanWorkflow(root, text)
+
+		// get runs-on labels
+		runners := workflowRunners(root)
+
+		// get secrets and variables references
+		secrets := workflowSecrets(root)
+
+		// get triggers and schedules
+		triggers := workflowTriggersWithFilters(root)
+		schedules := workflowSchedules(root)
+
+		// get every step using an action, for the --uses lookup
+		usesSteps := workflowUsesSteps(root)
+
+		// get deprecated workflow commands
+		commands := deprecatedCommands(root, text)
+
+		// check timeouts, concurrency and job structure
+		lint := lintWorkflow(root)
+
+		// put it all together
+		wfs = append(wfs, ActionWorkflow{
+			Path: e.Path,
+			URL: fmt.Sprintf(
+				"https://%s/%s/%s/blob/%s/%s",
+				hostname,
+				r.Owner.Login,
+				r.Name,
+				branch,
+				e.Path,
+			),
+			Uses:        uniqueUses(uses),
+			Permissions: uniquePermissions(permissions),
+			RiskScore:   riskScore(risks),
+			Risks:       risks,
+			Findings:    findings,
+			Runners:     runners,
+			Secrets:     secrets,
+			Triggers:    triggers,
+			Schedules:   schedules,
+			Commands:    commands,
+			Lint:        lint,
+			UsesSteps:   usesSteps,
+			Error:       wfError,
+		})
 	}
 
+	var score int
+	for _, w := range wfs {
+		score += w.RiskScore
+	}
+
+	return ActionUsesReport{
+		Owner:      r.Owner.Login,
+		Repo:       r.Name,
+		Branch:     branch,
+		Visibility: strings.ToLower(r.Visibility),
+		Archived:   r.IsArchived,
+		Fork:       r.IsFork,
+		RiskScore:  score,
+		Workflows:  wfs,
+	}
+}
+
+// saveWorkflowsView outputs the uses, permissions and findings of every workflow
+func saveWorkflowsView(res []ActionUsesReport) error {
+	var rows [][]string
+
 	for _, r := range res {
 		for _, w := range r.Workflows {
-			var data = []string{
+			rows = append(rows, []string{
 				r.Owner,
 				r.Repo,
+				r.Branch,
+				r.Visibility,
+				fmt.Sprintf("%t", r.Archived),
+				fmt.Sprintf("%t", r.Fork),
 				w.Path,
 				strings.Join(usesToString(w.Uses), ", "),
 				strings.Join(w.Permissions, ", "),
-			}
+				strings.Join(risksToString(w.Risks), ", "),
+				fmt.Sprintf("%d", w.RiskScore),
+				fmt.Sprintf("%d", r.RiskScore),
+				strings.Join(findingsToString(w.Findings), ", "),
+				w.Error,
+			})
+		}
+	}
 
-			td = append(td, data)
-			if csvPath != "" {
-				actionsReport.AddData(data)
-			}
+	return saveActionsReport(
+		[]string{"owner", "repo", "branch", "visibility", "archived?", "fork?", "workflow_path", "uses", "permissions", "risks", "risk_score", "repo_risk_score", "findings", "error"},
+		rows,
+		res,
+		mdActionsTemplate,
+	)
+}
+
+// saveActionsReport renders the table and saves the CSV, JSON and MD files of an actions report view
+func saveActionsReport(header []string, rows [][]string, res interface{}, tmpl string) (err error) {
+	var td = pterm.TableData{header}
+
+	// start CSV file
+	if csvPath != "" {
+		actionsReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		actionsReport.SetHeader(header)
+	}
+
+	for _, data := range rows {
+		td = append(td, data)
+
+		if csvPath != "" {
+			actionsReport.AddData(data)
 		}
 	}
 
@@ -384,16 +623,38 @@ func GetActionsReport(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	if jsonPath != "" {
-		err = utils.SaveJsonReport(jsonPath, res)
+		if err = utils.SaveJsonReport(jsonPath, res); err != nil {
+			return err
+		}
 	}
 
 	if mdPath != "" {
-		err = utils.SaveMDReport(mdPath, mdActionsTemplate, res)
+		err = utils.SaveMDReport(mdPath, tmpl, res)
 	}
 
 	return err
 }
 
+// skipRepository reports whether a repository is excluded from the report,
+// archived and forked repositories are excluded unless requested
+func skipRepository(r ActionUsesRepository) bool {
+	if (r.IsArchived && !includeArchived) || (r.IsFork && !includeForks) {
+		return true
+	}
+
+	if internal && r.Visibility != "INTERNAL" {
+		return true
+	}
+	if private && r.Visibility != "PRIVATE" {
+		return true
+	}
+	if public && r.Visibility != "PUBLIC" {
+		return true
+	}
+
+	return false
+}
+
 func excludeGitHubAuthored(s string) bool {
 	if exclude {
 		return !strings.HasPrefix(s, "actions/") && !strings.HasPrefix(s, "github/")
@@ -422,7 +683,7 @@ func getPermissions(p interface{}) []string {
 	switch p := p.(type) {
 	case string:
 		permissions = append(permissions, p)
-	case map[interface{}]interface{}:
+	case map[string]interface{}:
 		for k, v := range p {
 			permissions = append(permissions, fmt.Sprintf("%v: %v", k, v))
 		}
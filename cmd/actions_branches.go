@@ -0,0 +1,126 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/graphql"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	ActionBranchesQuery struct {
+		Repository struct {
+			Refs struct {
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   graphql.String
+				}
+				Nodes []ActionBranch
+			} `graphql:"refs(refPrefix: \"refs/heads/\", first: 10, after: $page, query: $query)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+)
+
+type (
+	ActionBranch struct {
+		Name   string
+		Target struct {
+			Commit struct {
+				File *struct {
+					Object struct {
+						Tree WorkflowTree `graphql:"... on Tree"`
+					}
+				} `graphql:"file(path: \".github/workflows\")"`
+			} `graphql:"... on Commit"`
+		}
+	}
+)
+
+// fetchWorkflowBranches returns the branches of a repository matching pattern,
+// together with their workflow files
+func fetchWorkflowBranches(r ActionUsesRepository, pattern string) ([]ActionBranch, error) {
+	var branches []ActionBranch
+
+	variables := map[string]interface{}{
+		"owner": graphql.String(r.Owner.Login),
+		"name":  graphql.String(r.Name),
+		"query": graphql.String(branchQuery(pattern)),
+		"page":  (*graphql.String)(nil),
+	}
+
+	var i = 1
+	for {
+		sp.Suffix = fmt.Sprintf(
+			" fetching actions report %s %s",
+			utils.Cyan(r.NameWithOwner),
+			utils.HiBlack(fmt.Sprintf("(branches page %d)", i)),
+		)
+
+		if err := graphqlClient.Query("ActionBranches", &ActionBranchesQuery, variables); err != nil {
+			return nil, err
+		}
+
+		for _, b := range ActionBranchesQuery.Repository.Refs.Nodes {
+			if matchBranch(pattern, b.Name) {
+				branches = append(branches, b)
+			}
+		}
+
+		if !ActionBranchesQuery.Repository.Refs.PageInfo.HasNextPage {
+			break
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+
+		variables["page"] = &ActionBranchesQuery.Repository.Refs.PageInfo.EndCursor
+		i++
+	}
+
+	return branches, nil
+}
+
+// branchQuery returns the literal prefix of a branch glob, used to narrow down
+// the branches fetched before they are matched against the full pattern
+func branchQuery(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+
+	return pattern
+}
+
+// matchBranch reports whether branch name matches the glob pattern, `*` matches all branches
+func matchBranch(pattern, name string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+
+	ok, err := path.Match(pattern, name)
+
+	return err == nil && ok
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func Test_branchQuery(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"*", ""},
+		{"release/*", "release/"},
+		{"v1.?", "v1."},
+		{"main", "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := branchQuery(tt.pattern); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_matchBranch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*", "main", true},
+		{"*", "feature/foo", true},
+		{"release/*", "release/v1", true},
+		{"release/*", "release/v1/hotfix", false},
+		{"release/*", "main", false},
+		{"main", "main", true},
+		{"[", "main", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := matchBranch(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("Expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
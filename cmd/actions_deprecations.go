@@ -0,0 +1,379 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stoe/gh-report/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	deprecationRuntime = "runtime"
+	deprecationCommand = "command"
+)
+
+var (
+	// deprecatedRuntimes are the `runs.using` values GitHub deprecated or removed
+	deprecatedRuntimes = map[string]bool{
+		"node12": true,
+		"node16": true,
+		"node20": true,
+	}
+
+	// workflowCommands are the deprecated workflow commands and their replacements
+	workflowCommands = map[string]string{
+		"::set-output": "use the GITHUB_OUTPUT environment file",
+		"::save-state": "use the GITHUB_STATE environment file",
+	}
+
+	// actionRuntimes caches the runtime of every action by owner/repo[/path]@ref
+	actionRuntimes = map[string]ActionRuntime{}
+
+	mdActionsDeprecationsTemplate = `# GitHub Actions Deprecations Report
+
+| Owner | Repo | Branch | Workflow | Job | Step | Type | Subject | Detail | Deprecated |
+| ----- | ---- | ------ | -------- | --- | ---- | ---- | ------- | ------ | ---------- |
+{{ range .Deprecations }}| {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .Workflow }}{{ if .Line }}#L{{ .Line }}{{ end }} | {{ .Job }} | {{ .Step }} | {{ .Type }} | ` + "`" + `{{ .Subject }}` + "`" + ` | {{ .Detail }} | {{ if .Deprecated }}:warning: yes{{ else }}no{{ end }} |
+{{ end }}
+## Affected Repositories
+
+| Owner | Repo | Deprecated Runtimes | Deprecated Commands |
+| ----- | ---- | ------------------: | ------------------: |
+{{ range .Repositories }}| {{ .Owner }} | {{ .Repo }} | {{ .Runtimes }} | {{ .Commands }} |
+{{ end }}
+`
+)
+
+type (
+	WorkflowCommand struct {
+		Job     string `json:"job"`
+		Step    string `json:"step,omitempty"`
+		Command string `json:"command"`
+		Line    int    `json:"line,omitempty"`
+		Column  int    `json:"column,omitempty"`
+	}
+
+	ActionRuntime struct {
+		Using string `json:"using,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+
+	ActionDeprecation struct {
+		Owner      string `json:"owner"`
+		Repo       string `json:"repo"`
+		Branch     string `json:"branch"`
+		Workflow   string `json:"workflow"`
+		Job        string `json:"job"`
+		Step       string `json:"step,omitempty"`
+		Type       string `json:"type"`
+		Subject    string `json:"subject"`
+		Detail     string `json:"detail"`
+		Deprecated bool   `json:"deprecated"`
+		Line       int    `json:"line,omitempty"`
+	}
+
+	AffectedRepository struct {
+		Owner    string `json:"owner"`
+		Repo     string `json:"repo"`
+		Runtimes int    `json:"deprecated_runtimes"`
+		Commands int    `json:"deprecated_commands"`
+	}
+
+	ActionDeprecationReport struct {
+		Deprecations []ActionDeprecation  `json:"deprecations"`
+		Repositories []AffectedRepository `json:"repositories"`
+	}
+)
+
+// deprecatedCommands returns the deprecated workflow commands used in `run:` steps
+func deprecatedCommands(root *yaml.Node, text string) []WorkflowCommand {
+	var commands []WorkflowCommand
+
+	lines := strings.Split(text, "\n")
+
+	workflowSteps(root, func(job string, step *yaml.Node) {
+		run := mappingValue(step, "run")
+		if run == nil || run.Kind != yaml.ScalarNode {
+			return
+		}
+
+		for _, c := range slices.Sorted(maps.Keys(workflowCommands)) {
+			if !strings.Contains(run.Value, c) {
+				continue
+			}
+
+			line, column := locate(lines, run, c, 0)
+
+			commands = append(commands, WorkflowCommand{
+				Job:     job,
+				Step:    stepName(step),
+				Command: c,
+				Line:    line,
+				Column:  column,
+			})
+		}
+	})
+
+	return commands
+}
+
+// actionSource returns the repository, directory and ref of the metadata file of an action.
+// It returns false for docker images, reusable workflows and refs that are expressions.
+func actionSource(r ActionUsesReport, action, ref string) (string, string, string, bool) {
+	if strings.HasPrefix(action, "docker://") || strings.Contains(action, "/.github/workflows/") || strings.Contains(ref, "${{") {
+		return "", "", "", false
+	}
+
+	// local actions are resolved in the repository at the analyzed branch
+	if strings.HasPrefix(action, "./") {
+		return r.Owner + "/" + r.Repo, strings.TrimPrefix(action, "./"), r.Branch, true
+	}
+
+	parts := strings.SplitN(action, "/", 3)
+	if len(parts) < 2 {
+		return "", "", "", false
+	}
+
+	var dir string
+	if len(parts) == 3 {
+		dir = parts[2]
+	}
+
+	return parts[0] + "/" + parts[1], dir, ref, true
+}
+
+// runtimeKey returns the actionRuntimes cache key of an action
+func runtimeKey(nwo, dir, ref string) string {
+	return strings.TrimSuffix(path.Join(nwo, dir), "/") + "@" + ref
+}
+
+// fetchActionRuntimes fetches the runtime of every action used in the report into actionRuntimes
+func fetchActionRuntimes(res []ActionUsesReport) {
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			for _, s := range w.UsesSteps {
+				if !excludeGitHubAuthored(s.Action) {
+					continue
+				}
+
+				nwo, dir, ref, ok := actionSource(r, s.Action, s.Ref)
+				if !ok {
+					continue
+				}
+
+				key := runtimeKey(nwo, dir, ref)
+				if _, ok := actionRuntimes[key]; ok {
+					continue
+				}
+
+				sp.Suffix = fmt.Sprintf(
+					" fetching action runtime %s",
+					utils.Cyan(key),
+				)
+
+				using, err := fetchActionRuntime(nwo, dir, ref)
+				if err != nil {
+					actionRuntimes[key] = ActionRuntime{Error: err.Error()}
+				} else {
+					actionRuntimes[key] = ActionRuntime{Using: using}
+				}
+
+				// sleep for 1 second to avoid rate limiting
+				time.Sleep(1 * time.Second)
+			}
+		}
+	}
+}
+
+// fetchActionRuntime returns the `runs.using` value of the action.yml or action.yaml in dir at ref
+func fetchActionRuntime(nwo, dir, ref string) (string, error) {
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		var file struct {
+			Content  string `json:"content"`
+			Encoding string `json:"encoding"`
+		}
+
+		endpoint := fmt.Sprintf("repos/%s/contents/%s", nwo, path.Join(dir, name))
+		if ref != "" {
+			endpoint = fmt.Sprintf("%s?ref=%s", endpoint, url.QueryEscape(ref))
+		}
+
+		if err := restClient.Get(endpoint, &file); err != nil {
+			if strings.Contains(err.Error(), "404") {
+				continue
+			}
+
+			return "", err
+		}
+
+		text, err := decodeContent(file.Content, file.Encoding)
+		if err != nil {
+			return "", err
+		}
+
+		var metadata struct {
+			Runs struct {
+				Using string `yaml:"using"`
+			} `yaml:"runs"`
+		}
+
+		if err := yaml.Unmarshal([]byte(text), &metadata); err != nil {
+			return "", fmt.Errorf("parsing %s failed: %w", name, err)
+		}
+
+		return strings.ToLower(metadata.Runs.Using), nil
+	}
+
+	return "", fmt.Errorf("action metadata not found")
+}
+
+// findDeprecations returns the runtime of every action used and every deprecated workflow command,
+// together with the repositories affected by deprecations
+func findDeprecations(res []ActionUsesReport) ActionDeprecationReport {
+	report := ActionDeprecationReport{
+		Deprecations: []ActionDeprecation{},
+		Repositories: []AffectedRepository{},
+	}
+
+	for _, r := range res {
+		affected := AffectedRepository{Owner: r.Owner, Repo: r.Repo}
+
+		for _, w := range r.Workflows {
+			for _, s := range w.UsesSteps {
+				if !excludeGitHubAuthored(s.Action) {
+					continue
+				}
+
+				d := ActionDeprecation{
+					Owner:    r.Owner,
+					Repo:     r.Repo,
+					Branch:   r.Branch,
+					Workflow: w.Path,
+					Job:      s.Job,
+					Step:     s.Step,
+					Type:     deprecationRuntime,
+					Subject:  s.Action,
+				}
+
+				if s.Ref != "" {
+					d.Subject = fmt.Sprintf("%s@%s", s.Action, s.Ref)
+				}
+
+				if strings.HasPrefix(s.Action, "docker://") {
+					d.Detail = "docker"
+				} else if nwo, dir, ref, ok := actionSource(r, s.Action, s.Ref); ok {
+					rt := actionRuntimes[runtimeKey(nwo, dir, ref)]
+
+					d.Detail = rt.Using
+					if rt.Error != "" {
+						d.Detail = rt.Error
+					}
+
+					d.Deprecated = deprecatedRuntimes[rt.Using]
+				} else {
+					// reusable workflows have no runtime
+					continue
+				}
+
+				if d.Deprecated {
+					affected.Runtimes++
+				}
+
+				report.Deprecations = append(report.Deprecations, d)
+			}
+
+			for _, c := range w.Commands {
+				report.Deprecations = append(report.Deprecations, ActionDeprecation{
+					Owner:      r.Owner,
+					Repo:       r.Repo,
+					Branch:     r.Branch,
+					Workflow:   w.Path,
+					Job:        c.Job,
+					Step:       c.Step,
+					Type:       deprecationCommand,
+					Subject:    c.Command,
+					Detail:     workflowCommands[c.Command],
+					Deprecated: true,
+					Line:       c.Line,
+				})
+
+				affected.Commands++
+			}
+		}
+
+		if affected.Runtimes > 0 || affected.Commands > 0 {
+			report.Repositories = append(report.Repositories, affected)
+		}
+	}
+
+	return report
+}
+
+// saveDeprecationsView outputs the runtime of every action used and the deprecated workflow commands
+func saveDeprecationsView(res []ActionUsesReport) error {
+	report := findDeprecations(res)
+
+	var rows [][]string
+	var deprecated int
+
+	for _, d := range report.Deprecations {
+		rows = append(rows, []string{
+			d.Owner,
+			d.Repo,
+			d.Branch,
+			d.Workflow,
+			d.Job,
+			d.Step,
+			d.Type,
+			d.Subject,
+			d.Detail,
+			fmt.Sprintf("%t", d.Deprecated),
+		})
+
+		if d.Deprecated {
+			deprecated++
+		}
+	}
+
+	if !silent {
+		fmt.Printf(
+			"Found %s deprecations across %s repositories\n\n",
+			utils.Bold(deprecated),
+			utils.Bold(len(report.Repositories)),
+		)
+	}
+
+	return saveActionsReport(
+		[]string{"owner", "repo", "branch", "workflow_path", "job", "step", "type", "subject", "detail", "deprecated?"},
+		rows,
+		report,
+		mdActionsDeprecationsTemplate,
+	)
+}
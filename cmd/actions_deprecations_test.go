@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_deprecatedCommands(t *testing.T) {
+	text := `on: push
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - name: version
+        run: |
+          echo "building"
+          echo "::set-output name=version::1.0.0"
+      - id: cache
+        run: echo "::save-state name=key::abc"
+      - run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+`
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	want := []WorkflowCommand{
+		{Job: "build", Step: "version", Command: "::set-output", Line: 9, Column: 17},
+		{Job: "build", Step: "cache", Command: "::save-state", Line: 11, Column: 20},
+	}
+
+	got := deprecatedCommands(root, text)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deprecatedCommands() = %v, want %v", got, want)
+	}
+}
+
+func Test_actionSource(t *testing.T) {
+	r := ActionUsesReport{Owner: "octo-org", Repo: "octo-repo", Branch: "main"}
+
+	tests := []struct {
+		action string
+		ref    string
+		want   string
+		ok     bool
+	}{
+		{"actions/checkout", "v2", "actions/checkout@v2", true},
+		{"octo-org/actions/setup", "v1", "octo-org/actions/setup@v1", true},
+		{"./.github/actions/setup", "", "octo-org/octo-repo/.github/actions/setup@main", true},
+		{"octo-org/shared/.github/workflows/ci.yml", "v2", "", false},
+		{"docker://alpine:3.20", "", "", false},
+		{"actions/checkout", "${{ inputs.ref }}", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			nwo, dir, ref, ok := actionSource(r, tt.action, tt.ref)
+			if ok != tt.ok {
+				t.Fatalf("Expected %t, got %t", tt.ok, ok)
+			}
+
+			if ok && runtimeKey(nwo, dir, ref) != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, runtimeKey(nwo, dir, ref))
+			}
+		})
+	}
+}
+
+func Test_findDeprecations(t *testing.T) {
+	actionRuntimes = map[string]ActionRuntime{
+		"actions/checkout@v2":      {Using: "node12"},
+		"actions/checkout@v4":      {Using: "node20"},
+		"actions/checkout@v5":      {Using: "node24"},
+		"octo-org/composite@v1":    {Using: "composite"},
+		"octo-org/missing@v1":      {Error: "action metadata not found"},
+		"octo-org/octo-repo/x@dev": {Using: "node16"},
+	}
+	defer func() { actionRuntimes = map[string]ActionRuntime{} }()
+
+	res := []ActionUsesReport{
+		{
+			Owner:  "octo-org",
+			Repo:   "octo-repo",
+			Branch: "dev",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{
+						{Job: "build", Action: "actions/checkout", Ref: "v2"},
+						{Job: "build", Action: "./x"},
+						{Job: "build", Action: "docker://alpine:3.20"},
+						{Job: "call", Action: "octo-org/shared/.github/workflows/ci.yml", Ref: "v2"},
+					},
+					Commands: []WorkflowCommand{
+						{Job: "build", Step: "version", Command: "::set-output", Line: 9},
+					},
+				},
+			},
+		},
+		{
+			Owner:  "octo-org",
+			Repo:   "clean-repo",
+			Branch: "main",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{
+						{Job: "build", Action: "actions/checkout", Ref: "v5"},
+						{Job: "build", Action: "octo-org/composite", Ref: "v1"},
+						{Job: "build", Action: "octo-org/missing", Ref: "v1"},
+					},
+				},
+			},
+		},
+	}
+
+	got := findDeprecations(res)
+
+	var details []string
+	var deprecated int
+	for _, d := range got.Deprecations {
+		details = append(details, d.Detail)
+
+		if d.Deprecated {
+			deprecated++
+		}
+	}
+
+	want := []string{
+		"node12",
+		"node16",
+		"docker",
+		"use the GITHUB_OUTPUT environment file",
+		"node24",
+		"composite",
+		"action metadata not found",
+	}
+	if !reflect.DeepEqual(details, want) {
+		t.Errorf("Expected %v, got %v", want, details)
+	}
+
+	if deprecated != 3 {
+		t.Errorf("Expected 3 deprecations, got %d", deprecated)
+	}
+
+	wantRepos := []AffectedRepository{{Owner: "octo-org", Repo: "octo-repo", Runtimes: 2, Commands: 1}}
+	if !reflect.DeepEqual(got.Repositories, wantRepos) {
+		t.Errorf("Expected %v, got %v", wantRepos, got.Repositories)
+	}
+}
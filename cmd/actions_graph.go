@@ -0,0 +1,285 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+const (
+	graphRepository       = "repository"
+	graphWorkflow         = "workflow"
+	graphAction           = "action"
+	graphReusableWorkflow = "reusable-workflow"
+)
+
+var (
+	graphFormats = []string{"dot", "mermaid", "json"}
+
+	mdActionsGraphTemplate = "# GitHub Actions Dependency Graph\n\n```mermaid\n{{ .Mermaid }}```\n"
+
+	dotShapes = map[string]string{
+		graphRepository:       "box",
+		graphWorkflow:         "note",
+		graphAction:           "ellipse",
+		graphReusableWorkflow: "component",
+	}
+
+	mermaidShapes = map[string][2]string{
+		graphRepository:       {`["`, `"]`},
+		graphWorkflow:         {`("`, `")`},
+		graphAction:           {`(["`, `"])`},
+		graphReusableWorkflow: {`[["`, `"]]`},
+	}
+)
+
+type (
+	ActionGraph struct {
+		Nodes []GraphNode `json:"nodes"`
+		Edges []GraphEdge `json:"edges"`
+	}
+
+	GraphNode struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+		Type  string `json:"type"`
+	}
+
+	GraphEdge struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Label string `json:"label,omitempty"`
+	}
+)
+
+// buildActionGraph builds the graph of repositories, their workflows, and the actions
+// and reusable workflows those use. Workflows are keyed by branch, a reusable workflow
+// called at a ref and the workflow read from that branch of its own repository are the same node.
+func buildActionGraph(res []ActionUsesReport) ActionGraph {
+	g := ActionGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+
+	nodes := map[string]int{}
+	edges := map[GraphEdge]bool{}
+
+	node := func(label, typ string) string {
+		key := strings.ToLower(label)
+
+		if i, ok := nodes[key]; ok {
+			// a workflow that is called by another workflow is a reusable workflow
+			if typ == graphReusableWorkflow {
+				g.Nodes[i].Type = typ
+			}
+
+			return g.Nodes[i].ID
+		}
+
+		nodes[key] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, GraphNode{ID: fmt.Sprintf("n%d", len(g.Nodes)+1), Label: label, Type: typ})
+
+		return g.Nodes[nodes[key]].ID
+	}
+
+	edge := func(e GraphEdge) {
+		if !edges[e] {
+			edges[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+	}
+
+	for _, r := range res {
+		repository := fmt.Sprintf("%s/%s", r.Owner, r.Repo)
+		rid := node(repository, graphRepository)
+
+		for _, w := range r.Workflows {
+			wid := node(workflowRef(fmt.Sprintf("%s/%s", repository, w.Path), r.Branch), graphWorkflow)
+			edge(GraphEdge{From: rid, To: wid})
+
+			for _, s := range w.UsesSteps {
+				if !excludeGitHubAuthored(s.Action) {
+					continue
+				}
+
+				label := s.Action
+				// local actions and reusable workflows live in the same repository
+				if strings.HasPrefix(label, "./") {
+					label = fmt.Sprintf("%s/%s", repository, strings.TrimPrefix(label, "./"))
+				}
+
+				typ := graphAction
+				if strings.Contains(label, "/.github/workflows/") {
+					typ = graphReusableWorkflow
+
+					// a local reusable workflow is called from the same branch
+					ref := s.Ref
+					if strings.HasPrefix(s.Action, "./") {
+						ref = r.Branch
+					}
+
+					label = workflowRef(label, ref)
+				}
+
+				edge(GraphEdge{From: wid, To: node(label, typ), Label: s.Ref})
+			}
+		}
+	}
+
+	return g
+}
+
+// dot renders the graph in Graphviz DOT format
+func (g ActionGraph) dot() string {
+	var b strings.Builder
+
+	b.WriteString("digraph actions {\n  rankdir=LR;\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%q shape=%s];\n", n.ID, n.Label, dotShapes[n.Type])
+	}
+
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -> %s [label=%q];\n", e.From, e.To, e.Label)
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", e.From, e.To)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// mermaid renders the graph as a Mermaid flowchart
+func (g ActionGraph) mermaid() string {
+	var b strings.Builder
+
+	escape := strings.NewReplacer(`"`, "#quot;", "|", "#124;")
+
+	b.WriteString("flowchart LR\n")
+
+	for _, n := range g.Nodes {
+		s := mermaidShapes[n.Type]
+		fmt.Fprintf(&b, "  %s%s%s%s\n", n.ID, s[0], escape.Replace(n.Label), s[1])
+	}
+
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", e.From, escape.Replace(e.Label), e.To)
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", e.From, e.To)
+		}
+	}
+
+	return b.String()
+}
+
+// render renders the graph in format dot, mermaid or json
+func (g ActionGraph) render(format string) (string, error) {
+	switch format {
+	case "dot":
+		return g.dot(), nil
+	case "mermaid":
+		return g.mermaid(), nil
+	}
+
+	b, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b) + "\n", nil
+}
+
+// saveGraphView outputs the dependency graph in the `--graph` format, to the terminal and the `--graph-output` file,
+// the MD file always renders the Mermaid form
+func saveGraphView(res []ActionUsesReport) (err error) {
+	g := buildActionGraph(res)
+
+	text, err := g.render(graphFormat)
+	if err != nil {
+		return err
+	}
+
+	if !silent {
+		fmt.Print(text)
+	}
+
+	if graphOutput != "" {
+		if err = utils.SaveTextReport(graphOutput, strings.ToUpper(graphFormat), text); err != nil {
+			return err
+		}
+	}
+
+	labels := map[string]GraphNode{}
+	for _, n := range g.Nodes {
+		labels[n.ID] = n
+	}
+
+	// start CSV file
+	if csvPath != "" {
+		actionsReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		actionsReport.SetHeader([]string{"from", "from_type", "to", "to_type", "ref"})
+
+		for _, e := range g.Edges {
+			actionsReport.AddData([]string{
+				labels[e.From].Label,
+				labels[e.From].Type,
+				labels[e.To].Label,
+				labels[e.To].Type,
+				e.Label,
+			})
+		}
+
+		actionsReport.Save()
+	}
+
+	if jsonPath != "" {
+		if err = utils.SaveJsonReport(jsonPath, g); err != nil {
+			return err
+		}
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdActionsGraphTemplate, struct{ Mermaid string }{g.mermaid()})
+	}
+
+	return err
+}
+
+// workflowRef returns the workflow path qualified with the branch or ref it was read from,
+// so the same workflow on different branches is kept apart
+func workflowRef(path, ref string) string {
+	if ref == "" {
+		return path
+	}
+
+	return path + "@" + ref
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func graphTestReport() []ActionUsesReport {
+	return []ActionUsesReport{
+		{
+			Owner:  "octo-org",
+			Repo:   "shared",
+			Branch: "main",
+			Workflows: []ActionWorkflow{
+				{
+					Path:      ".github/workflows/deploy.yml",
+					UsesSteps: []WorkflowUsesStep{{Job: "deploy", Action: "actions/checkout", Ref: "v4"}},
+				},
+			},
+		},
+		{
+			Owner:  "octo-org",
+			Repo:   "app",
+			Branch: "main",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{
+						{Job: "deploy", Action: "octo-org/shared/.github/workflows/deploy.yml", Ref: "main"},
+						{Job: "build", Action: "actions/checkout", Ref: "v4"},
+						{Job: "lint", Action: "actions/checkout", Ref: "v4"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_buildActionGraph(t *testing.T) {
+	g := buildActionGraph(graphTestReport())
+
+	if len(g.Nodes) != 5 {
+		t.Fatalf("Expected 5 nodes, got %d: %v", len(g.Nodes), g.Nodes)
+	}
+
+	// the shared workflow is called by app, so it is a reusable workflow
+	if g.Nodes[1].Label != "octo-org/shared/.github/workflows/deploy.yml@main" || g.Nodes[1].Type != graphReusableWorkflow {
+		t.Errorf("Expected reusable workflow node, got %+v", g.Nodes[1])
+	}
+
+	// repo -> workflow, workflow -> action (twice), repo -> workflow, workflow -> reusable workflow
+	if len(g.Edges) != 5 {
+		t.Fatalf("Expected 5 edges, got %d: %v", len(g.Edges), g.Edges)
+	}
+
+	want := GraphEdge{From: "n5", To: "n2", Label: "main"}
+	if g.Edges[3] != want {
+		t.Errorf("Expected edge %v, got %v", want, g.Edges[3])
+	}
+}
+
+func Test_buildActionGraph_Branches(t *testing.T) {
+	res := []ActionUsesReport{
+		{Owner: "octo-org", Repo: "app", Branch: "main", Workflows: []ActionWorkflow{{Path: ".github/workflows/ci.yml"}}},
+		{Owner: "octo-org", Repo: "app", Branch: "dev", Workflows: []ActionWorkflow{{Path: ".github/workflows/ci.yml"}}},
+	}
+
+	g := buildActionGraph(res)
+
+	// one repository, and the workflow once per branch
+	if len(g.Nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d: %v", len(g.Nodes), g.Nodes)
+	}
+	if g.Nodes[1].Label != "octo-org/app/.github/workflows/ci.yml@main" || g.Nodes[2].Label != "octo-org/app/.github/workflows/ci.yml@dev" {
+		t.Errorf("Expected a workflow node per branch, got %v", g.Nodes)
+	}
+}
+
+func Test_ActionGraph_dot(t *testing.T) {
+	got := buildActionGraph(graphTestReport()).dot()
+
+	for _, want := range []string{
+		"digraph actions {",
+		`n1 [label="octo-org/shared" shape=box];`,
+		`n2 [label="octo-org/shared/.github/workflows/deploy.yml@main" shape=component];`,
+		`n2 -> n3 [label="v4"];`,
+		"n1 -> n2;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected dot output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func Test_ActionGraph_mermaid(t *testing.T) {
+	got := buildActionGraph(graphTestReport()).mermaid()
+
+	for _, want := range []string{
+		"flowchart LR",
+		`n1["octo-org/shared"]`,
+		`n3(["actions/checkout"])`,
+		"n5 -->|main| n2",
+		"n1 --> n2",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected mermaid output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func Test_ActionGraph_render(t *testing.T) {
+	g := buildActionGraph(graphTestReport())
+
+	tests := []struct {
+		format string
+		prefix string
+	}{
+		{format: "dot", prefix: "digraph actions {"},
+		{format: "mermaid", prefix: "flowchart LR"},
+		{format: "json", prefix: "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := g.render(tt.format)
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+			if !strings.HasPrefix(got, tt.prefix) {
+				t.Errorf("Expected %s output to start with %q, got:\n%s", tt.format, tt.prefix, got)
+			}
+		})
+	}
+}
+
+func Test_saveGraphView(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "graph.dot")
+
+	graphFormat, graphOutput, silent = "dot", p, true
+	defer func() { graphFormat, graphOutput, silent = "", "", false }()
+
+	if err := saveGraphView(graphTestReport()); err != nil {
+		t.Fatalf("saveGraphView() error = %v", err)
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "digraph actions {") {
+		t.Errorf("Expected DOT file, got:\n%s", b)
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// concurrencyTriggers are the events that should cancel superseded runs with a concurrency group
+	concurrencyTriggers = []string{"push", "pull_request", "pull_request_target"}
+
+	mdActionsLintTemplate = `# GitHub Actions Lint Report
+
+| Owner | Repo | Branch | Workflow | Jobs | Total Jobs | Matrix | Missing Timeout | Missing Concurrency |
+| ----- | ---- | ------ | -------- | ---: | ---------: | ------ | --------------- | ------------------- |
+{{ range . }}| {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .Workflow }} | {{ .Jobs }} | {{ .TotalJobs }} | {{ range $i, $v := .Matrices }}{{ if $i }}<br/>{{ end }}{{ $v.Job }}: {{ if $v.Dynamic }}dynamic{{ else }}{{ $v.Size }}{{ end }}{{ end }} | {{ range $i, $v := .MissingTimeout }}{{ if $i }}<br/>{{ end }}` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ if .MissingConcurrency }}:warning: yes{{ else }}no{{ end }} |
+{{ end }}
+`
+)
+
+type (
+	WorkflowLint struct {
+		Jobs               int         `json:"jobs"`
+		TotalJobs          int         `json:"total_jobs"`
+		Matrices           []JobMatrix `json:"matrices,omitempty"`
+		MissingTimeout     []string    `json:"missing_timeout,omitempty"`
+		MissingConcurrency bool        `json:"missing_concurrency"`
+	}
+
+	JobMatrix struct {
+		Job     string `json:"job"`
+		Size    int    `json:"size"`
+		Dynamic bool   `json:"dynamic,omitempty"`
+	}
+
+	ActionLintReport struct {
+		Owner    string `json:"owner"`
+		Repo     string `json:"repo"`
+		Branch   string `json:"branch"`
+		Workflow string `json:"workflow"`
+		WorkflowLint
+	}
+)
+
+// lintWorkflow checks the workflow for jobs without `timeout-minutes`, push and pull request
+// workflows without `concurrency`, and counts its jobs including the matrix combinations
+func lintWorkflow(root *yaml.Node) WorkflowLint {
+	var lint WorkflowLint
+
+	jobs := mappingValue(root, "jobs")
+	allConcurrency := true
+
+	mappingPairs(jobs, func(k, v *yaml.Node) {
+		lint.Jobs++
+
+		if mappingValue(v, "concurrency") == nil {
+			allConcurrency = false
+		}
+
+		// jobs calling a reusable workflow cannot set a timeout
+		if mappingValue(v, "uses") == nil && mappingValue(v, "timeout-minutes") == nil {
+			lint.MissingTimeout = append(lint.MissingTimeout, k.Value)
+		}
+
+		matrix := mappingValue(mappingValue(v, "strategy"), "matrix")
+		if matrix == nil {
+			lint.TotalJobs++
+			return
+		}
+
+		size, dynamic := matrixSize(matrix)
+		lint.Matrices = append(lint.Matrices, JobMatrix{Job: k.Value, Size: size, Dynamic: dynamic})
+
+		// the size of a dynamic matrix is only known at run time, count it as a single job
+		if dynamic || size == 0 {
+			lint.TotalJobs++
+		} else {
+			lint.TotalJobs += size
+		}
+	})
+
+	if hasTrigger(root, concurrencyTriggers...) && mappingValue(root, "concurrency") == nil {
+		lint.MissingConcurrency = lint.Jobs == 0 || !allConcurrency
+	}
+
+	return lint
+}
+
+// matrixSize returns the number of jobs a strategy matrix creates, after applying its
+// `exclude` and `include` entries. It reports whether the matrix is built from expressions.
+func matrixSize(matrix *yaml.Node) (int, bool) {
+	if matrix.Kind != yaml.MappingNode {
+		return 0, true
+	}
+
+	var keys []string
+	var combinations []map[string]string
+
+	dynamic := false
+
+	mappingPairs(matrix, func(k, v *yaml.Node) {
+		if k.Value == "include" || k.Value == "exclude" {
+			if v.Kind != yaml.SequenceNode {
+				dynamic = true
+			}
+
+			return
+		}
+
+		if v.Kind != yaml.SequenceNode {
+			dynamic = true
+			return
+		}
+
+		keys = append(keys, k.Value)
+
+		// cartesian product of the matrix values
+		var next []map[string]string
+		for _, e := range v.Content {
+			if len(keys) == 1 {
+				next = append(next, map[string]string{k.Value: nodeKey(e)})
+				continue
+			}
+
+			for _, c := range combinations {
+				n := map[string]string{k.Value: nodeKey(e)}
+				for ck, cv := range c {
+					n[ck] = cv
+				}
+
+				next = append(next, n)
+			}
+		}
+
+		combinations = next
+	})
+
+	if dynamic {
+		return 0, true
+	}
+
+	// matches reports whether a combination has the values of entry for every original matrix key
+	matches := func(c map[string]string, entry *yaml.Node) bool {
+		for _, key := range keys {
+			if v := mappingValue(entry, key); v != nil && c[key] != nodeKey(v) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if exclude := mappingValue(matrix, "exclude"); exclude != nil {
+		for _, e := range exclude.Content {
+			var kept []map[string]string
+
+			for _, c := range combinations {
+				if !matches(c, resolveNode(e)) {
+					kept = append(kept, c)
+				}
+			}
+
+			combinations = kept
+		}
+	}
+
+	size := len(combinations)
+
+	// include entries that do not match an existing combination add a job
+	if include := mappingValue(matrix, "include"); include != nil {
+		for _, e := range include.Content {
+			matched := false
+
+			for _, c := range combinations {
+				if matches(c, resolveNode(e)) {
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				size++
+			}
+		}
+	}
+
+	return size, false
+}
+
+// nodeKey returns a comparable representation of a YAML node
+func nodeKey(n *yaml.Node) string {
+	n = resolveNode(n)
+
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+
+	b, _ := yaml.Marshal(n)
+
+	return string(b)
+}
+
+// matricesToString formats the matrix sizes of a workflow
+func matricesToString(matrices []JobMatrix) []string {
+	var s = []string{}
+
+	for _, m := range matrices {
+		if m.Dynamic {
+			s = append(s, fmt.Sprintf("%s: dynamic", m.Job))
+		} else {
+			s = append(s, fmt.Sprintf("%s: %d", m.Job, m.Size))
+		}
+	}
+
+	return s
+}
+
+// saveLintView outputs the job structure, timeouts and concurrency of every workflow
+func saveLintView(res []ActionUsesReport) error {
+	var lints []ActionLintReport
+	var rows [][]string
+
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			lints = append(lints, ActionLintReport{
+				Owner:        r.Owner,
+				Repo:         r.Repo,
+				Branch:       r.Branch,
+				Workflow:     w.Path,
+				WorkflowLint: w.Lint,
+			})
+
+			rows = append(rows, []string{
+				r.Owner,
+				r.Repo,
+				r.Branch,
+				w.Path,
+				fmt.Sprintf("%d", w.Lint.Jobs),
+				fmt.Sprintf("%d", w.Lint.TotalJobs),
+				strings.Join(matricesToString(w.Lint.Matrices), ", "),
+				strings.Join(w.Lint.MissingTimeout, ", "),
+				fmt.Sprintf("%t", w.Lint.MissingConcurrency),
+			})
+		}
+	}
+
+	return saveActionsReport(
+		[]string{"owner", "repo", "branch", "workflow_path", "jobs", "total_jobs", "matrix", "missing_timeout", "missing_concurrency"},
+		rows,
+		lints,
+		mdActionsLintTemplate,
+	)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_lintWorkflow(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want WorkflowLint
+	}{
+		{
+			name: "missing timeout and concurrency",
+			text: `on: [push, pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  test:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+        node: [18, 20, 22]
+    steps:
+      - run: make test
+  call:
+    uses: octo-org/shared/.github/workflows/ci.yml@v2
+`,
+			want: WorkflowLint{
+				Jobs:               3,
+				TotalJobs:          8,
+				Matrices:           []JobMatrix{{Job: "test", Size: 6}},
+				MissingTimeout:     []string{"build"},
+				MissingConcurrency: true,
+			},
+		},
+		{
+			name: "workflow concurrency",
+			text: `on: push
+concurrency:
+  group: ${{ github.ref }}
+  cancel-in-progress: true
+jobs:
+  build:
+    timeout-minutes: 10
+`,
+			want: WorkflowLint{Jobs: 1, TotalJobs: 1},
+		},
+		{
+			name: "job concurrency on every job",
+			text: `on: pull_request
+jobs:
+  build:
+    timeout-minutes: 10
+    concurrency: build-${{ github.ref }}
+`,
+			want: WorkflowLint{Jobs: 1, TotalJobs: 1},
+		},
+		{
+			name: "schedule needs no concurrency",
+			text: `on:
+  schedule:
+    - cron: '0 0 * * *'
+jobs:
+  build:
+    timeout-minutes: 10
+    strategy:
+      matrix: ${{ fromJSON(needs.setup.outputs.matrix) }}
+`,
+			want: WorkflowLint{
+				Jobs:      1,
+				TotalJobs: 1,
+				Matrices:  []JobMatrix{{Job: "build", Dynamic: true}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWorkflow(tt.text)
+			if err != nil {
+				t.Fatalf("parseWorkflow() error = %v", err)
+			}
+
+			if got := lintWorkflow(root); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintWorkflow() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_matrixSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  string
+		size    int
+		dynamic bool
+	}{
+		{
+			name:   "cartesian product",
+			matrix: "os: [a, b]\nnode: [1, 2, 3]",
+			size:   6,
+		},
+		{
+			name:   "exclude",
+			matrix: "os: [a, b]\nnode: [1, 2, 3]\nexclude:\n  - os: a\n    node: 1\n  - os: b",
+			size:   2,
+		},
+		{
+			name:   "include extending and adding combinations",
+			matrix: "os: [a, b]\ninclude:\n  - os: a\n    experimental: true\n  - os: c",
+			size:   3,
+		},
+		{
+			name:   "include only",
+			matrix: "include:\n  - os: a\n  - os: b",
+			size:   2,
+		},
+		{
+			name:    "expression dimension",
+			matrix:  "os: ${{ fromJSON(inputs.os) }}\nnode: [1, 2]",
+			dynamic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWorkflow(tt.matrix)
+			if err != nil {
+				t.Fatalf("parseWorkflow() error = %v", err)
+			}
+
+			size, dynamic := matrixSize(root)
+			if size != tt.size || dynamic != tt.dynamic {
+				t.Errorf("Expected %d (dynamic %t), got %d (dynamic %t)", tt.size, tt.dynamic, size, dynamic)
+			}
+		})
+	}
+}
+
+func Test_matricesToString(t *testing.T) {
+	got := matricesToString([]JobMatrix{{Job: "test", Size: 6}, {Job: "build", Dynamic: true}})
+	want := []string{"test: 6", "build: dynamic"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	riskDefaultPermissions     = "default-permissions"
+	riskWriteAll               = "write-all"
+	riskPullRequestTargetWrite = "pull-request-target-write"
+	riskIDTokenWrite           = "id-token-write"
+	riskJobEscalation          = "job-escalation"
+)
+
+var (
+	// riskScores weighs each permission risk, the workflow and repository
+	// risk scores are the sum of the weights of all risks found
+	riskScores = map[string]int{
+		riskDefaultPermissions:     3,
+		riskWriteAll:               5,
+		riskPullRequestTargetWrite: 8,
+		riskIDTokenWrite:           2,
+		riskJobEscalation:          3,
+	}
+)
+
+type (
+	WorkflowRisk struct {
+		Rule    string `json:"rule"`
+		Job     string `json:"job,omitempty"`
+		Message string `json:"message"`
+		Score   int    `json:"score"`
+		Line    int    `json:"line,omitempty"`
+		Column  int    `json:"column,omitempty"`
+	}
+)
+
+// analyzePermissions evaluates the workflow and job level permissions of a workflow
+// and returns the risks found.
+func analyzePermissions(root *yaml.Node) []WorkflowRisk {
+	var risks []WorkflowRisk
+
+	prTarget := hasTrigger(root, "pull_request_target")
+
+	wk, wp := mappingEntry(root, "permissions")
+	if wp != nil {
+		risks = append(risks, scopeRisks(wk, wp, "", prTarget)...)
+	}
+
+	var inheriting []string
+	var inheritingNode *yaml.Node
+
+	mappingPairs(mappingValue(root, "jobs"), func(k, v *yaml.Node) {
+		jk, jp := mappingEntry(v, "permissions")
+
+		if jp == nil {
+			// job inherits the workflow permissions, or the default token permissions if there are none
+			if wp == nil {
+				inheriting = append(inheriting, k.Value)
+
+				if inheritingNode == nil {
+					inheritingNode = k
+				}
+			}
+
+			return
+		}
+
+		risks = append(risks, scopeRisks(jk, jp, k.Value, prTarget)...)
+
+		if wp != nil {
+			if escalated := escalatedScopes(wp, jp); len(escalated) > 0 {
+				risks = append(risks, newWorkflowRisk(
+					riskJobEscalation,
+					k.Value,
+					fmt.Sprintf("job grants %s beyond the workflow permissions", strings.Join(escalated, ", ")),
+					jk,
+				))
+			}
+		}
+	})
+
+	if len(inheriting) > 0 {
+		risks = append(risks, newWorkflowRisk(
+			riskDefaultPermissions,
+			"",
+			fmt.Sprintf(
+				"no permissions block, %s inherit the default GITHUB_TOKEN permissions",
+				strings.Join(inheriting, ", "),
+			),
+			inheritingNode,
+		))
+	}
+
+	return risks
+}
+
+// scopeRisks returns the risks of a single permissions block, at workflow level if job is empty
+func scopeRisks(k, p *yaml.Node, job string, prTarget bool) []WorkflowRisk {
+	var risks []WorkflowRisk
+
+	if p.Kind == yaml.ScalarNode {
+		if p.Value == "write-all" {
+			risks = append(risks, newWorkflowRisk(riskWriteAll, job, "permissions: write-all", k))
+
+			if prTarget {
+				risks = append(risks, newWorkflowRisk(
+					riskPullRequestTargetWrite,
+					job,
+					"contents: write on a pull_request_target workflow",
+					k,
+				))
+			}
+		}
+
+		return risks
+	}
+
+	mappingPairs(p, func(sk, sv *yaml.Node) {
+		if sv.Value != "write" {
+			return
+		}
+
+		switch sk.Value {
+		case "id-token":
+			risks = append(risks, newWorkflowRisk(riskIDTokenWrite, job, "id-token: write", sk))
+		case "contents":
+			if prTarget {
+				risks = append(risks, newWorkflowRisk(
+					riskPullRequestTargetWrite,
+					job,
+					"contents: write on a pull_request_target workflow",
+					sk,
+				))
+			}
+		}
+	})
+
+	return risks
+}
+
+// escalatedScopes returns the scopes the job permissions grant write access to,
+// while the workflow permissions do not
+func escalatedScopes(wp, jp *yaml.Node) []string {
+	var escalated []string
+
+	// write-all on the job is reported on its own
+	if jp.Kind != yaml.MappingNode {
+		return escalated
+	}
+
+	mappingPairs(jp, func(sk, sv *yaml.Node) {
+		if sv.Value == "write" && permissionLevel(wp, sk.Value) != "write" {
+			escalated = append(escalated, fmt.Sprintf("%s: write", sk.Value))
+		}
+	})
+
+	sort.Strings(escalated)
+
+	return escalated
+}
+
+// permissionLevel returns the access level a permissions block grants to scope
+func permissionLevel(p *yaml.Node, scope string) string {
+	switch p.Kind {
+	case yaml.ScalarNode:
+		switch p.Value {
+		case "write-all":
+			return "write"
+		case "read-all":
+			return "read"
+		}
+	case yaml.MappingNode:
+		if v := mappingValue(p, scope); v != nil {
+			return v.Value
+		}
+	}
+
+	return "none"
+}
+
+func newWorkflowRisk(rule, job, message string, n *yaml.Node) WorkflowRisk {
+	r := WorkflowRisk{
+		Rule:    rule,
+		Job:     job,
+		Message: message,
+		Score:   riskScores[rule],
+	}
+
+	if n != nil {
+		r.Line = n.Line
+		r.Column = n.Column
+	}
+
+	return r
+}
+
+// riskScore returns the sum of the scores of all risks
+func riskScore(risks []WorkflowRisk) int {
+	var s int
+
+	for _, r := range risks {
+		s += r.Score
+	}
+
+	return s
+}
+
+func risksToString(risks []WorkflowRisk) []string {
+	var s = []string{}
+
+	for _, r := range risks {
+		if r.Job != "" {
+			s = append(s, fmt.Sprintf("%s (%s)", r.Rule, r.Job))
+		} else {
+			s = append(s, r.Rule)
+		}
+	}
+
+	return s
+}
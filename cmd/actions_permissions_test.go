@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func Test_analyzePermissions(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantRules []string
+		wantScore int
+	}{
+		{
+			name: "no permissions block",
+			text: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+`,
+			wantRules: []string{riskDefaultPermissions},
+			wantScore: 3,
+		},
+		{
+			name: "read-all at workflow level",
+			text: `on: push
+permissions: read-all
+jobs:
+  build:
+    runs-on: ubuntu-latest
+`,
+			wantRules: nil,
+			wantScore: 0,
+		},
+		{
+			name: "write-all at workflow level",
+			text: `on: push
+permissions: write-all
+jobs:
+  build:
+    runs-on: ubuntu-latest
+`,
+			wantRules: []string{riskWriteAll},
+			wantScore: 5,
+		},
+		{
+			name: "contents write on pull_request_target",
+			text: `on: pull_request_target
+permissions:
+  contents: write
+jobs:
+  build:
+    runs-on: ubuntu-latest
+`,
+			wantRules: []string{riskPullRequestTargetWrite},
+			wantScore: 8,
+		},
+		{
+			name: "id-token write",
+			text: `on: push
+permissions:
+  id-token: write
+  contents: read
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+`,
+			wantRules: []string{riskIDTokenWrite},
+			wantScore: 2,
+		},
+		{
+			name: "job level escalation",
+			text: `on: push
+permissions:
+  contents: read
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+      packages: write
+`,
+			wantRules: []string{riskJobEscalation},
+			wantScore: 3,
+		},
+		{
+			name: "job level permissions only",
+			text: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+  test:
+    runs-on: ubuntu-latest
+`,
+			wantRules: []string{riskDefaultPermissions},
+			wantScore: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWorkflow(tt.text)
+			if err != nil {
+				t.Fatalf("parseWorkflow() error = %v", err)
+			}
+
+			risks := analyzePermissions(root)
+
+			if len(risks) != len(tt.wantRules) {
+				t.Fatalf("Expected %d risks, got %d: %v", len(tt.wantRules), len(risks), risks)
+			}
+			for i, r := range risks {
+				if r.Rule != tt.wantRules[i] {
+					t.Errorf("Expected rule %s, got %s", tt.wantRules[i], r.Rule)
+				}
+				if r.Line == 0 {
+					t.Errorf("Expected rule %s to have a line number", r.Rule)
+				}
+			}
+			if got := riskScore(risks); got != tt.wantScore {
+				t.Errorf("Expected risk score %d, got %d", tt.wantScore, got)
+			}
+		})
+	}
+}
+
+func Test_analyzePermissions_EscalationMessage(t *testing.T) {
+	root, err := parseWorkflow(`on: push
+permissions: read-all
+jobs:
+  release:
+    permissions:
+      packages: write
+      contents: write
+`)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	risks := analyzePermissions(root)
+	if len(risks) != 1 {
+		t.Fatalf("Expected 1 risk, got %d", len(risks))
+	}
+
+	want := "job grants contents: write, packages: write beyond the workflow permissions"
+	if risks[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, risks[0].Message)
+	}
+	if risks[0].Job != "release" {
+		t.Errorf("Expected job release, got %s", risks[0].Job)
+	}
+	if risks[0].Line != 5 {
+		t.Errorf("Expected line 5, got %d", risks[0].Line)
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	matrixRefRegex = regexp.MustCompile(`^\$\{\{\s*matrix\.([\w-]+)\s*\}\}$`)
+
+	// githubHostedRegex matches the labels of standard GitHub-hosted runners
+	githubHostedRegex = regexp.MustCompile(`^(ubuntu-(latest|\d+\.\d+)(-arm)?|windows-(latest|\d+)(-arm)?|macos-(latest|\d+)(-intel)?)$`)
+
+	mdActionsRunnersTemplate = `# GitHub Actions Runners Report
+
+| Level | Owner | Repo | Branch | Workflow | Runner | Group | Type | Jobs |
+| ----- | ----- | ---- | ------ | -------- | ------ | ----- | ---- | ---: |
+{{ range . }}| {{ .Level }} | {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .Workflow }} | {{ range $i, $v := .Labels }}{{ if $i }}, {{ end }}` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ .Group }} | {{ .Type }} | {{ .Jobs }} |
+{{ end }}
+`
+)
+
+type (
+	WorkflowRunner struct {
+		Job    string   `json:"job"`
+		Labels []string `json:"labels,omitempty"`
+		Group  string   `json:"group,omitempty"`
+	}
+
+	ActionRunnerReport struct {
+		Level    string   `json:"level"`
+		Owner    string   `json:"owner"`
+		Repo     string   `json:"repo"`
+		Branch   string   `json:"branch,omitempty"`
+		Workflow string   `json:"workflow,omitempty"`
+		Labels   []string `json:"labels"`
+		Group    string   `json:"group,omitempty"`
+		Type     string   `json:"type"`
+		Jobs     int      `json:"jobs"`
+	}
+)
+
+// workflowRunners returns the runners every job of the workflow runs on.
+// Jobs with a matrix runs-on return one runner per matrix value.
+func workflowRunners(root *yaml.Node) []WorkflowRunner {
+	var runners []WorkflowRunner
+
+	mappingPairs(mappingValue(root, "jobs"), func(k, v *yaml.Node) {
+		runsOn := mappingValue(v, "runs-on")
+		if runsOn == nil {
+			return
+		}
+
+		switch runsOn.Kind {
+		case yaml.ScalarNode, yaml.SequenceNode:
+			for _, labels := range expandLabels(v, runsOn) {
+				runners = append(runners, WorkflowRunner{Job: k.Value, Labels: labels})
+			}
+		case yaml.MappingNode:
+			var group string
+			if g := mappingValue(runsOn, "group"); g != nil {
+				group = g.Value
+			}
+
+			labels := [][]string{nil}
+			if l := mappingValue(runsOn, "labels"); l != nil {
+				labels = expandLabels(v, l)
+			}
+
+			for _, l := range labels {
+				runners = append(runners, WorkflowRunner{Job: k.Value, Labels: l, Group: group})
+			}
+		}
+	})
+
+	return runners
+}
+
+// expandLabels returns the label sets of a runs-on scalar or sequence,
+// resolving `${{ matrix.<key> }}` references against the job's strategy matrix
+func expandLabels(job, n *yaml.Node) [][]string {
+	var elems []*yaml.Node
+
+	if n.Kind == yaml.SequenceNode {
+		for _, e := range n.Content {
+			elems = append(elems, resolveNode(e))
+		}
+	} else {
+		elems = []*yaml.Node{n}
+	}
+
+	sets := [][]string{{}}
+
+	for _, e := range elems {
+		values := [][]string{{e.Value}}
+
+		if m := matrixRefRegex.FindStringSubmatch(e.Value); m != nil {
+			if mv := matrixValues(job, m[1]); len(mv) > 0 {
+				values = mv
+			}
+		}
+
+		var next [][]string
+		for _, s := range sets {
+			for _, v := range values {
+				next = append(next, append(append([]string{}, s...), v...))
+			}
+		}
+		sets = next
+	}
+
+	return sets
+}
+
+// matrixValues returns the values of key in the job's strategy matrix, including `include` entries.
+// A value can itself be a list of labels.
+func matrixValues(job *yaml.Node, key string) [][]string {
+	var values [][]string
+
+	matrix := mappingValue(mappingValue(job, "strategy"), "matrix")
+
+	add := func(n *yaml.Node) {
+		switch n.Kind {
+		case yaml.ScalarNode:
+			values = append(values, []string{n.Value})
+		case yaml.SequenceNode:
+			var labels []string
+			for _, l := range n.Content {
+				labels = append(labels, resolveNode(l).Value)
+			}
+			values = append(values, labels)
+		}
+	}
+
+	if v := mappingValue(matrix, key); v != nil {
+		if v.Kind == yaml.SequenceNode {
+			for _, e := range v.Content {
+				add(resolveNode(e))
+			}
+		} else {
+			add(v)
+		}
+	}
+
+	if include := mappingValue(matrix, "include"); include != nil && include.Kind == yaml.SequenceNode {
+		for _, e := range include.Content {
+			if v := mappingValue(e, key); v != nil {
+				add(v)
+			}
+		}
+	}
+
+	return values
+}
+
+// runnerType classifies a runner as github-hosted, self-hosted, runner-group, larger or expression
+func runnerType(r WorkflowRunner) string {
+	for _, l := range r.Labels {
+		if l == "self-hosted" {
+			return "self-hosted"
+		}
+	}
+
+	if r.Group != "" {
+		return "runner-group"
+	}
+
+	for _, l := range r.Labels {
+		if strings.Contains(l, "${{") {
+			return "expression"
+		}
+	}
+
+	for _, l := range r.Labels {
+		if !githubHostedRegex.MatchString(l) {
+			return "larger"
+		}
+	}
+
+	return "github-hosted"
+}
+
+// aggregateRunners counts the jobs per runner label set of every workflow,
+// followed by the roll-ups per repository, across its branches, and per organization
+func aggregateRunners(res []ActionUsesReport) []ActionRunnerReport {
+	var runners []ActionRunnerReport
+
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			var keys []string
+			counts := map[string]*ActionRunnerReport{}
+
+			for _, wr := range w.Runners {
+				key := wr.Group + "|" + strings.Join(wr.Labels, ",")
+
+				if _, ok := counts[key]; !ok {
+					keys = append(keys, key)
+					counts[key] = &ActionRunnerReport{
+						Level:    "workflow",
+						Owner:    r.Owner,
+						Repo:     r.Repo,
+						Branch:   r.Branch,
+						Workflow: w.Path,
+						Labels:   wr.Labels,
+						Group:    wr.Group,
+						Type:     runnerType(wr),
+					}
+				}
+
+				counts[key].Jobs++
+			}
+
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				runners = append(runners, *counts[key])
+			}
+		}
+	}
+
+	repositories := rollupRunners(runners, "repository", func(r ActionRunnerReport) ActionRunnerReport {
+		return ActionRunnerReport{Owner: r.Owner, Repo: r.Repo}
+	})
+	organizations := rollupRunners(runners, "organization", func(r ActionRunnerReport) ActionRunnerReport {
+		return ActionRunnerReport{Owner: r.Owner}
+	})
+
+	return append(append(runners, repositories...), organizations...)
+}
+
+// rollupRunners sums the jobs of the workflow runners per runner label set of the scope returned by scope
+func rollupRunners(runners []ActionRunnerReport, level string, scope func(ActionRunnerReport) ActionRunnerReport) []ActionRunnerReport {
+	var keys []string
+	counts := map[string]*ActionRunnerReport{}
+
+	for _, r := range runners {
+		s := scope(r)
+		key := strings.ToLower(s.Owner+"/"+s.Repo) + "|" + r.Group + "|" + strings.Join(r.Labels, ",")
+
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+
+			s.Level = level
+			s.Labels = r.Labels
+			s.Group = r.Group
+			s.Type = r.Type
+			counts[key] = &s
+		}
+
+		counts[key].Jobs += r.Jobs
+	}
+
+	sort.Strings(keys)
+
+	rollup := make([]ActionRunnerReport, 0, len(keys))
+	for _, key := range keys {
+		rollup = append(rollup, *counts[key])
+	}
+
+	return rollup
+}
+
+// saveRunnersView outputs the runs-on labels aggregated by workflow, repository and organization
+func saveRunnersView(res []ActionUsesReport) error {
+	runners := aggregateRunners(res)
+
+	var rows [][]string
+	for _, r := range runners {
+		rows = append(rows, []string{
+			r.Level,
+			r.Owner,
+			r.Repo,
+			r.Branch,
+			r.Workflow,
+			strings.Join(r.Labels, ", "),
+			r.Group,
+			r.Type,
+			fmt.Sprintf("%d", r.Jobs),
+		})
+	}
+
+	return saveActionsReport(
+		[]string{"level", "owner", "repo", "branch", "workflow_path", "labels", "group", "type", "jobs"},
+		rows,
+		runners,
+		mdActionsRunnersTemplate,
+	)
+}
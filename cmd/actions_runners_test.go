@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_workflowRunners(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []WorkflowRunner
+	}{
+		{
+			name: "string label",
+			text: "jobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want: []WorkflowRunner{{Job: "build", Labels: []string{"ubuntu-latest"}}},
+		},
+		{
+			name: "array of labels",
+			text: "jobs:\n  build:\n    runs-on: [self-hosted, linux, x64]\n",
+			want: []WorkflowRunner{{Job: "build", Labels: []string{"self-hosted", "linux", "x64"}}},
+		},
+		{
+			name: "group and labels",
+			text: "jobs:\n  build:\n    runs-on:\n      group: ubuntu-runners\n      labels: ubuntu-22.04-16core\n",
+			want: []WorkflowRunner{{Job: "build", Labels: []string{"ubuntu-22.04-16core"}, Group: "ubuntu-runners"}},
+		},
+		{
+			name: "group only",
+			text: "jobs:\n  build:\n    runs-on:\n      group: ubuntu-runners\n",
+			want: []WorkflowRunner{{Job: "build", Group: "ubuntu-runners"}},
+		},
+		{
+			name: "matrix expression",
+			text: `jobs:
+  test:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+        include:
+          - os: macos-14
+    runs-on: ${{ matrix.os }}
+`,
+			want: []WorkflowRunner{
+				{Job: "test", Labels: []string{"ubuntu-latest"}},
+				{Job: "test", Labels: []string{"windows-latest"}},
+				{Job: "test", Labels: []string{"macos-14"}},
+			},
+		},
+		{
+			name: "matrix of label sets",
+			text: `jobs:
+  test:
+    strategy:
+      matrix:
+        runner:
+          - [self-hosted, linux]
+          - [self-hosted, windows]
+    runs-on: ${{ matrix.runner }}
+`,
+			want: []WorkflowRunner{
+				{Job: "test", Labels: []string{"self-hosted", "linux"}},
+				{Job: "test", Labels: []string{"self-hosted", "windows"}},
+			},
+		},
+		{
+			name: "unresolved expression",
+			text: "jobs:\n  build:\n    runs-on: ${{ inputs.runner }}\n",
+			want: []WorkflowRunner{{Job: "build", Labels: []string{"${{ inputs.runner }}"}}},
+		},
+		{
+			name: "reusable workflow call",
+			text: "jobs:\n  call:\n    uses: ./.github/workflows/ci.yml\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWorkflow(tt.text)
+			if err != nil {
+				t.Fatalf("parseWorkflow() error = %v", err)
+			}
+
+			got := workflowRunners(root)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workflowRunners() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runnerType(t *testing.T) {
+	tests := []struct {
+		runner WorkflowRunner
+		want   string
+	}{
+		{runner: WorkflowRunner{Labels: []string{"ubuntu-latest"}}, want: "github-hosted"},
+		{runner: WorkflowRunner{Labels: []string{"ubuntu-24.04-arm"}}, want: "github-hosted"},
+		{runner: WorkflowRunner{Labels: []string{"macos-14"}}, want: "github-hosted"},
+		{runner: WorkflowRunner{Labels: []string{"macos-14-xlarge"}}, want: "larger"},
+		{runner: WorkflowRunner{Labels: []string{"ubuntu-22.04-16core"}}, want: "larger"},
+		{runner: WorkflowRunner{Labels: []string{"self-hosted", "linux"}}, want: "self-hosted"},
+		{runner: WorkflowRunner{Group: "ubuntu-runners"}, want: "runner-group"},
+		{runner: WorkflowRunner{Labels: []string{"${{ inputs.runner }}"}}, want: "expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := runnerType(tt.runner); got != tt.want {
+				t.Errorf("runnerType(%v) = %v, want %v", tt.runner, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_aggregateRunners(t *testing.T) {
+	res := []ActionUsesReport{
+		{
+			Owner:  "octo-org",
+			Repo:   "octo-repo",
+			Branch: "main",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					Runners: []WorkflowRunner{
+						{Job: "build", Labels: []string{"ubuntu-latest"}},
+						{Job: "test", Labels: []string{"ubuntu-latest"}},
+						{Job: "deploy", Labels: []string{"self-hosted", "linux"}},
+					},
+				},
+			},
+		},
+	}
+
+	res = append(res, ActionUsesReport{
+		Owner:  "octo-org",
+		Repo:   "other-repo",
+		Branch: "main",
+		Workflows: []ActionWorkflow{
+			{
+				Path:    ".github/workflows/ci.yml",
+				Runners: []WorkflowRunner{{Job: "build", Labels: []string{"ubuntu-latest"}}},
+			},
+		},
+	})
+
+	got := aggregateRunners(res)
+	// 3 workflow runners, 3 repository and 2 organization roll-ups
+	if len(got) != 8 {
+		t.Fatalf("Expected 8 runners, got %d: %v", len(got), got)
+	}
+
+	if got[0].Type != "self-hosted" || got[0].Jobs != 1 {
+		t.Errorf("Expected 1 self-hosted job, got %d %s jobs", got[0].Jobs, got[0].Type)
+	}
+	if got[1].Type != "github-hosted" || got[1].Jobs != 2 {
+		t.Errorf("Expected 2 github-hosted jobs, got %d %s jobs", got[1].Jobs, got[1].Type)
+	}
+	if got[0].Branch != "main" {
+		t.Errorf("Expected branch main, got %s", got[0].Branch)
+	}
+
+	if r := got[4]; r.Level != "repository" || r.Repo != "octo-repo" || r.Type != "github-hosted" || r.Jobs != 2 {
+		t.Errorf("Expected a repository roll-up of 2 github-hosted jobs, got %+v", r)
+	}
+
+	if r := got[7]; r.Level != "organization" || r.Repo != "" || r.Type != "github-hosted" || r.Jobs != 3 {
+		t.Errorf("Expected an organization roll-up of 3 github-hosted jobs, got %+v", r)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+var (
+	// sarifRules holds the rule metadata of every finding and risk the actions report emits
+	sarifRules = []SarifRule{
+		newSarifRule(findingUnpinnedAction, "UnpinnedAction", "Action is not pinned to a full length commit SHA", "warning", "5.0"),
+		newSarifRule(findingUntrustedCheckout, "UntrustedCheckout", "Privileged workflow checks out untrusted pull request code", "error", "9.0"),
+		newSarifRule(findingScriptInjection, "ScriptInjection", "Script interpolates an attacker-controlled context", "error", "8.0"),
+		newSarifRule(riskDefaultPermissions, "DefaultPermissions", "Workflow inherits the default GITHUB_TOKEN permissions", "warning", "4.0"),
+		newSarifRule(riskWriteAll, "WriteAllPermissions", "Workflow or job grants write-all permissions", "error", "7.0"),
+		newSarifRule(riskPullRequestTargetWrite, "PullRequestTargetWrite", "pull_request_target workflow has contents: write permission", "error", "9.0"),
+		newSarifRule(riskIDTokenWrite, "IDTokenWrite", "Workflow or job can request an OIDC token", "note", "3.0"),
+		newSarifRule(riskJobEscalation, "JobPermissionEscalation", "Job grants permissions beyond the workflow permissions", "warning", "5.0"),
+	}
+)
+
+type (
+	SarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []SarifRun `json:"runs"`
+	}
+
+	SarifRun struct {
+		Tool    SarifTool     `json:"tool"`
+		Results []SarifResult `json:"results"`
+	}
+
+	SarifTool struct {
+		Driver SarifDriver `json:"driver"`
+	}
+
+	SarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []SarifRule `json:"rules"`
+	}
+
+	SarifRule struct {
+		ID                   string            `json:"id"`
+		Name                 string            `json:"name"`
+		ShortDescription     SarifMessage      `json:"shortDescription"`
+		DefaultConfiguration SarifRuleConfig   `json:"defaultConfiguration"`
+		Properties           SarifRuleProperty `json:"properties"`
+	}
+
+	SarifRuleConfig struct {
+		Level string `json:"level"`
+	}
+
+	SarifRuleProperty struct {
+		Tags             []string `json:"tags"`
+		SecuritySeverity string   `json:"security-severity"`
+	}
+
+	SarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	SarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   SarifMessage    `json:"message"`
+		Locations []SarifLocation `json:"locations"`
+	}
+
+	SarifLocation struct {
+		PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	SarifPhysicalLocation struct {
+		ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+		Region           SarifRegion           `json:"region"`
+	}
+
+	SarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId"`
+	}
+
+	SarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+)
+
+func newSarifRule(id, name, description, level, severity string) SarifRule {
+	return SarifRule{
+		ID:                   id,
+		Name:                 name,
+		ShortDescription:     SarifMessage{Text: description},
+		DefaultConfiguration: SarifRuleConfig{Level: level},
+		Properties: SarifRuleProperty{
+			Tags:             []string{"security", "actions"},
+			SecuritySeverity: severity,
+		},
+	}
+}
+
+// buildSarifLog converts the findings and permission risks of a repository's workflows into a SARIF log
+func buildSarifLog(r ActionUsesReport) SarifLog {
+	results := []SarifResult{}
+
+	for _, w := range r.Workflows {
+		for _, f := range w.Findings {
+			results = append(results, newSarifResult(f.Rule, f.Message, w.Path, f.Line, f.Column))
+		}
+
+		for _, k := range w.Risks {
+			results = append(results, newSarifResult(k.Rule, k.Message, w.Path, k.Line, k.Column))
+		}
+	}
+
+	return SarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []SarifRun{
+			{
+				Tool: SarifTool{
+					Driver: SarifDriver{
+						Name:           "gh-report",
+						Version:        RootCmd.Version,
+						InformationURI: "https://github.com/stoe/gh-report",
+						Rules:          sarifRules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func newSarifResult(rule, message, path string, line, column int) SarifResult {
+	var index int
+	var level string
+
+	for i, r := range sarifRules {
+		if r.ID == rule {
+			index = i
+			level = r.DefaultConfiguration.Level
+			break
+		}
+	}
+
+	// SARIF regions are 1-based
+	if line < 1 {
+		line = 1
+	}
+
+	return SarifResult{
+		RuleID:    rule,
+		RuleIndex: index,
+		Level:     level,
+		Message:   SarifMessage{Text: message},
+		Locations: []SarifLocation{
+			{
+				PhysicalLocation: SarifPhysicalLocation{
+					ArtifactLocation: SarifArtifactLocation{
+						URI:       path,
+						URIBaseID: "%SRCROOT%",
+					},
+					Region: SarifRegion{
+						StartLine:   line,
+						StartColumn: column,
+					},
+				},
+			},
+		},
+	}
+}
+
+// sarifPath returns the SARIF file path of a repository in directory dir,
+// including the branch when the workflows of several branches are analyzed
+func sarifPath(dir string, r ActionUsesReport) string {
+	if allBranches != "" {
+		return filepath.Join(dir, fmt.Sprintf("%s_%s_%s.sarif", r.Owner, r.Repo, strings.ReplaceAll(r.Branch, "/", "-")))
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.sarif", r.Owner, r.Repo))
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func Test_buildSarifLog(t *testing.T) {
+	r := ActionUsesReport{
+		Owner: "octo-org",
+		Repo:  "octo-repo",
+		Workflows: []ActionWorkflow{
+			{
+				Path: ".github/workflows/ci.yml",
+				Findings: []WorkflowFinding{
+					{Rule: findingScriptInjection, Message: "script interpolates untrusted input", Line: 12, Column: 16},
+				},
+				Risks: []WorkflowRisk{
+					{Rule: riskWriteAll, Message: "permissions: write-all", Line: 3, Column: 1},
+					{Rule: riskDefaultPermissions, Message: "no permissions block"},
+				},
+			},
+		},
+	}
+
+	log := buildSarifLog(r)
+
+	if log.Version != "2.1.0" {
+		t.Errorf("Expected version 2.1.0, got %s", log.Version)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("Expected 1 run, got %d", len(log.Runs))
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	rules := log.Runs[0].Tool.Driver.Rules
+	for _, res := range results {
+		if rules[res.RuleIndex].ID != res.RuleID {
+			t.Errorf("Expected rule index of %s to point to its rule, got %s", res.RuleID, rules[res.RuleIndex].ID)
+		}
+		if res.Locations[0].PhysicalLocation.ArtifactLocation.URI != ".github/workflows/ci.yml" {
+			t.Errorf("Expected workflow path as artifact location, got %s", res.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		}
+	}
+
+	if results[0].Level != "error" || results[0].Locations[0].PhysicalLocation.Region.StartLine != 12 {
+		t.Errorf("Expected error at line 12, got %s at line %d", results[0].Level, results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	}
+
+	// risks without position fall back to the first line
+	if results[2].Locations[0].PhysicalLocation.Region.StartLine != 1 {
+		t.Errorf("Expected line 1, got %d", results[2].Locations[0].PhysicalLocation.Region.StartLine)
+	}
+}
+
+func Test_sarifPath(t *testing.T) {
+	got := sarifPath("out", ActionUsesReport{Owner: "octo-org", Repo: "octo-repo"})
+	if got != "out/octo-org_octo-repo.sarif" {
+		t.Errorf("sarifPath() = %v, want out/octo-org_octo-repo.sarif", got)
+	}
+
+	allBranches = "release/*"
+	defer func() { allBranches = "" }()
+
+	got = sarifPath("out", ActionUsesReport{Owner: "octo-org", Repo: "octo-repo", Branch: "release/v1"})
+	if got != "out/octo-org_octo-repo_release-v1.sarif" {
+		t.Errorf("sarifPath() = %v, want out/octo-org_octo-repo_release-v1.sarif", got)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	secretRefRegex = regexp.MustCompile(`\b(secrets|vars)(?:\.([A-Za-z_][\w-]*)|\[\s*['"]([^'"]+)['"]\s*\])`)
+
+	mdActionsSecretsTemplate = `# GitHub Actions Secrets and Variables Report
+
+| Owner | Repo | Branch | Workflow | Job | Secrets | Variables | Inherit |
+| ----- | ---- | ------ | -------- | --- | ------- | --------- | ------- |
+{{ range . }}| {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .Workflow }} | {{ .Job }} | {{ range $i, $v := .Secrets }}{{ if $i }}<br/>{{ end }}` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ range $i, $v := .Variables }}{{ if $i }}<br/>{{ end }}` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ if .Inherit }}:warning: ` + "`" + `secrets: inherit` + "`" + `{{ end }} |
+{{ end }}
+`
+)
+
+type (
+	WorkflowSecrets struct {
+		Job       string   `json:"job,omitempty"`
+		Secrets   []string `json:"secrets,omitempty"`
+		Variables []string `json:"variables,omitempty"`
+		Inherit   bool     `json:"inherit,omitempty"`
+	}
+
+	ActionSecretsReport struct {
+		Owner     string   `json:"owner"`
+		Repo      string   `json:"repo"`
+		Branch    string   `json:"branch"`
+		Workflow  string   `json:"workflow"`
+		Job       string   `json:"job,omitempty"`
+		Secrets   []string `json:"secrets"`
+		Variables []string `json:"variables"`
+		Inherit   bool     `json:"inherit"`
+	}
+)
+
+// workflowSecrets returns the secrets and variables referenced by the workflow, per job.
+// References outside of jobs, e.g. in the workflow env, are returned without a job name.
+func workflowSecrets(root *yaml.Node) []WorkflowSecrets {
+	var refs []WorkflowSecrets
+
+	workflow := WorkflowSecrets{}
+	mappingPairs(root, func(k, v *yaml.Node) {
+		if k.Value != "jobs" {
+			collectSecretRefs(&workflow, v, false)
+		}
+	})
+
+	if len(workflow.Secrets) > 0 || len(workflow.Variables) > 0 {
+		refs = append(refs, sortedSecretRefs(workflow))
+	}
+
+	mappingPairs(mappingValue(root, "jobs"), func(k, v *yaml.Node) {
+		job := WorkflowSecrets{Job: k.Value}
+
+		// reusable workflow calls can pass all secrets of the caller
+		if s := mappingValue(v, "secrets"); s != nil && s.Kind == yaml.ScalarNode && s.Value == "inherit" {
+			job.Inherit = true
+		}
+
+		collectSecretRefs(&job, v, false)
+
+		if job.Inherit || len(job.Secrets) > 0 || len(job.Variables) > 0 {
+			refs = append(refs, sortedSecretRefs(job))
+		}
+	})
+
+	return refs
+}
+
+// collectSecretRefs adds the secrets and variables referenced in expressions below node n.
+// Values of `if` keys are expressions even without `${{ }}`.
+func collectSecretRefs(refs *WorkflowSecrets, n *yaml.Node, isExpr bool) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		var exprs []string
+
+		if isExpr {
+			exprs = []string{n.Value}
+		} else {
+			for _, m := range expressionRegex.FindAllStringSubmatch(n.Value, -1) {
+				exprs = append(exprs, m[1])
+			}
+		}
+
+		for _, e := range exprs {
+			for _, m := range secretRefRegex.FindAllStringSubmatch(e, -1) {
+				name := m[2]
+				if name == "" {
+					name = m[3]
+				}
+
+				if m[1] == "secrets" {
+					refs.Secrets = appendUnique(refs.Secrets, name)
+				} else {
+					refs.Variables = appendUnique(refs.Variables, name)
+				}
+			}
+		}
+	case yaml.MappingNode:
+		mappingPairs(n, func(k, v *yaml.Node) {
+			collectSecretRefs(refs, v, k.Value == "if")
+		})
+	case yaml.SequenceNode:
+		for _, e := range n.Content {
+			collectSecretRefs(refs, resolveNode(e), false)
+		}
+	}
+}
+
+func sortedSecretRefs(refs WorkflowSecrets) WorkflowSecrets {
+	sort.Strings(refs.Secrets)
+	sort.Strings(refs.Variables)
+
+	return refs
+}
+
+func appendUnique(s []string, e string) []string {
+	for _, v := range s {
+		if v == e {
+			return s
+		}
+	}
+
+	return append(s, e)
+}
+
+// saveSecretsView outputs the secrets and variables referenced per workflow and job
+func saveSecretsView(res []ActionUsesReport) error {
+	var refs []ActionSecretsReport
+	var rows [][]string
+
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			for _, s := range w.Secrets {
+				refs = append(refs, ActionSecretsReport{
+					Owner:     r.Owner,
+					Repo:      r.Repo,
+					Branch:    r.Branch,
+					Workflow:  w.Path,
+					Job:       s.Job,
+					Secrets:   s.Secrets,
+					Variables: s.Variables,
+					Inherit:   s.Inherit,
+				})
+
+				rows = append(rows, []string{
+					r.Owner,
+					r.Repo,
+					r.Branch,
+					w.Path,
+					s.Job,
+					strings.Join(s.Secrets, ", "),
+					strings.Join(s.Variables, ", "),
+					fmt.Sprintf("%t", s.Inherit),
+				})
+			}
+		}
+	}
+
+	return saveActionsReport(
+		[]string{"owner", "repo", "branch", "workflow_path", "job", "secrets", "variables", "secrets_inherit"},
+		rows,
+		refs,
+		mdActionsSecretsTemplate,
+	)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_workflowSecrets(t *testing.T) {
+	text := `on: push
+env:
+  REGISTRY: ${{ vars.REGISTRY }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    if: vars.ENABLE_BUILD == 'true'
+    steps:
+      - run: echo "$TOKEN"
+        env:
+          TOKEN: ${{ secrets.NPM_TOKEN || secrets['FALLBACK_TOKEN'] }}
+      - uses: docker/login-action@v3
+        with:
+          password: ${{ secrets.NPM_TOKEN }}
+  call:
+    uses: octo-org/shared/.github/workflows/deploy.yml@main
+    secrets: inherit
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "${{ github.sha }}"
+`
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	want := []WorkflowSecrets{
+		{Variables: []string{"REGISTRY"}},
+		{Job: "build", Secrets: []string{"FALLBACK_TOKEN", "NPM_TOKEN"}, Variables: []string{"ENABLE_BUILD"}},
+		{Job: "call", Inherit: true},
+	}
+
+	got := workflowSecrets(root)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("workflowSecrets() = %v, want %v", got, want)
+	}
+}
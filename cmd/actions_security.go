@@ -0,0 +1,296 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	findingUntrustedCheckout = "untrusted-checkout"
+	findingScriptInjection   = "script-injection"
+	findingUnpinnedAction    = "unpinned-action"
+)
+
+var (
+	expressionRegex = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+
+	// untrustedContexts are contexts an attacker controls, e.g. by opening an issue or pull request
+	untrustedContexts = []*regexp.Regexp{
+		regexp.MustCompile(`github\.event\.(issue|pull_request|discussion)\.(title|body)`),
+		regexp.MustCompile(`github\.event\.(comment|review|review_comment)\.body`),
+		regexp.MustCompile(`github\.event\.pages\b.*\.page_name`),
+		regexp.MustCompile(`github\.event\.(commits|head_commit)\b.*\.(message|author\.email|author\.name)`),
+		regexp.MustCompile(`github\.event\.pull_request\.head\.(ref|label|repo\.default_branch)`),
+		regexp.MustCompile(`github\.event\.workflow_run\.(head_branch|head_commit\.(message|author\.email|author\.name))`),
+		regexp.MustCompile(`github\.event\.workflow_run\.pull_requests\b.*\.head\.ref`),
+		regexp.MustCompile(`github\.head_ref`),
+	}
+
+	// untrustedRefs point to code of the pull request or workflow run that triggered the workflow
+	untrustedRefs = []*regexp.Regexp{
+		regexp.MustCompile(`github\.event\.pull_request\.head\.(sha|ref|repo\.full_name)`),
+		regexp.MustCompile(`github\.event\.workflow_run\.(head_sha|head_branch|head_repository\.full_name)`),
+		regexp.MustCompile(`github\.head_ref`),
+		regexp.MustCompile(`refs/pull/.*/(merge|head)`),
+	}
+
+	// untrustedCheckoutCommands check out pull request code from a run step
+	untrustedCheckoutCommands = regexp.MustCompile(`gh pr checkout|git fetch .*pull/`)
+
+	// pinnedRef is a full length commit SHA, the only immutable reference to an action
+	pinnedRef = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+type (
+	WorkflowFinding struct {
+		Rule     string `json:"rule"`
+		Severity string `json:"severity"`
+		Message  string `json:"message"`
+		Job      string `json:"job,omitempty"`
+		Step     string `json:"step,omitempty"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+	}
+)
+
+// scanWorkflow statically scans a workflow for dangerous triggers and script injections.
+// text is the workflow source, it is used to locate findings inside multi-line scalars.
+func scanWorkflow(root *yaml.Node, text string) []WorkflowFinding {
+	var findings []WorkflowFinding
+
+	lines := strings.Split(text, "\n")
+	privileged := hasTrigger(root, "pull_request_target", "workflow_run")
+
+	// reusable workflow calls
+	mappingPairs(mappingValue(root, "jobs"), func(k, v *yaml.Node) {
+		if uses := mappingValue(v, "uses"); uses != nil && !isPinned(uses.Value) {
+			findings = append(findings, unpinnedFinding(uses, k.Value, ""))
+		}
+	})
+
+	workflowSteps(root, func(job string, step *yaml.Node) {
+		name := stepName(step)
+		uses := mappingValue(step, "uses")
+		with := mappingValue(step, "with")
+
+		if run := mappingValue(step, "run"); run != nil && run.Kind == yaml.ScalarNode {
+			findings = append(findings, injectionFindings(lines, run, job, name)...)
+
+			if privileged && untrustedCheckoutCommands.MatchString(run.Value) {
+				line, col := locate(lines, run, untrustedCheckoutCommands.FindString(run.Value), 0)
+
+				findings = append(findings, WorkflowFinding{
+					Rule:     findingUntrustedCheckout,
+					Severity: "error",
+					Message:  "privileged workflow checks out untrusted pull request code",
+					Job:      job,
+					Step:     name,
+					Line:     line,
+					Column:   col,
+				})
+			}
+		}
+
+		if uses == nil {
+			return
+		}
+
+		if !isPinned(uses.Value) {
+			findings = append(findings, unpinnedFinding(uses, job, name))
+		}
+
+		// actions/github-script evaluates its script input as JavaScript
+		if strings.HasPrefix(uses.Value, "actions/github-script") {
+			if script := mappingValue(with, "script"); script != nil && script.Kind == yaml.ScalarNode {
+				findings = append(findings, injectionFindings(lines, script, job, name)...)
+			}
+		}
+
+		if privileged && strings.HasPrefix(uses.Value, "actions/checkout") {
+			for _, key := range []string{"ref", "repository"} {
+				v := mappingValue(with, key)
+				if v == nil || v.Kind != yaml.ScalarNode || !matchesAny(untrustedRefs, v.Value) {
+					continue
+				}
+
+				findings = append(findings, WorkflowFinding{
+					Rule:     findingUntrustedCheckout,
+					Severity: "error",
+					Message:  fmt.Sprintf("privileged workflow checks out untrusted pull request code (%s: %s)", key, v.Value),
+					Job:      job,
+					Step:     name,
+					Line:     v.Line,
+					Column:   v.Column,
+				})
+
+				break
+			}
+		}
+	})
+
+	return findings
+}
+
+// injectionFindings returns a finding for every expression in a script that interpolates an untrusted context
+func injectionFindings(lines []string, n *yaml.Node, job, step string) []WorkflowFinding {
+	var findings []WorkflowFinding
+
+	// occurrences of every expression, so repeated expressions are located one by one
+	seen := map[string]int{}
+
+	for _, m := range expressionRegex.FindAllStringSubmatch(n.Value, -1) {
+		nth := seen[m[0]]
+		seen[m[0]]++
+
+		if !matchesAny(untrustedContexts, m[1]) {
+			continue
+		}
+
+		line, col := locate(lines, n, m[0], nth)
+
+		findings = append(findings, WorkflowFinding{
+			Rule:     findingScriptInjection,
+			Severity: "error",
+			Message:  fmt.Sprintf("script interpolates untrusted %s", strings.TrimSpace(m[0])),
+			Job:      job,
+			Step:     step,
+			Line:     line,
+			Column:   col,
+		})
+	}
+
+	return findings
+}
+
+// locate returns the line and column of the nth occurrence (0-based) of needle within the span of node n.
+// It falls back to the position of n if needle is not found, e.g. if it spans multiple lines.
+func locate(lines []string, n *yaml.Node, needle string, nth int) (int, int) {
+	start, end := nodeSpan(lines, n)
+
+	for i := start; i < end; i++ {
+		offset := 0
+
+		for {
+			idx := strings.Index(lines[i][offset:], needle)
+			if idx < 0 {
+				break
+			}
+
+			if nth == 0 {
+				return i + 1, offset + idx + 1
+			}
+
+			nth--
+			offset += idx + len(needle)
+		}
+	}
+
+	return n.Line, n.Column
+}
+
+// nodeSpan returns the range of line indexes of a scalar node: its own line and,
+// for block scalars (| and >), the following lines until the indentation drops
+func nodeSpan(lines []string, n *yaml.Node) (int, int) {
+	start := n.Line - 1
+	if start < 0 || start >= len(lines) {
+		return 0, 0
+	}
+
+	end := start + 1
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		return start, end
+	}
+
+	// the first non-empty line determines the indentation of the block
+	indent := -1
+
+	for ; end < len(lines); end++ {
+		line := lines[end]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		i := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < 0 {
+			indent = i
+		}
+
+		if i < indent || indent == 0 {
+			break
+		}
+	}
+
+	return start, end
+}
+
+// isPinned reports whether uses references an action or reusable workflow by full commit SHA.
+// Local actions are considered pinned.
+func isPinned(uses string) bool {
+	if strings.HasPrefix(uses, "./") {
+		return true
+	}
+
+	if strings.HasPrefix(uses, "docker://") {
+		return strings.Contains(uses, "@sha256:")
+	}
+
+	a := strings.Split(uses, "@")
+
+	return len(a) == 2 && pinnedRef.MatchString(a[1])
+}
+
+func unpinnedFinding(uses *yaml.Node, job, step string) WorkflowFinding {
+	return WorkflowFinding{
+		Rule:     findingUnpinnedAction,
+		Severity: "warning",
+		Message:  fmt.Sprintf("%s is not pinned to a full length commit SHA", uses.Value),
+		Job:      job,
+		Step:     step,
+		Line:     uses.Line,
+		Column:   uses.Column,
+	}
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func findingsToString(findings []WorkflowFinding) []string {
+	var s = []string{}
+
+	for _, f := range findings {
+		s = append(s, fmt.Sprintf("%s (line %d)", f.Rule, f.Line))
+	}
+
+	return s
+}
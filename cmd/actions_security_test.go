@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_scanWorkflow(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantRules []string
+		wantLines []int
+	}{
+		{
+			name: "script injection in run step",
+			text: `on: issues
+jobs:
+  triage:
+    runs-on: ubuntu-latest
+    steps:
+      - name: echo title
+        run: |
+          echo "triaging"
+          echo "${{ github.event.issue.title }}"
+`,
+			wantRules: []string{findingScriptInjection},
+			wantLines: []int{9},
+		},
+		{
+			name: "head_ref in single line run step",
+			text: `on: pull_request
+jobs:
+  build:
+    steps:
+      - run: git checkout ${{ github.head_ref }}
+`,
+			wantRules: []string{findingScriptInjection},
+			wantLines: []int{5},
+		},
+		{
+			name: "safe context in run step",
+			text: `on: push
+jobs:
+  build:
+    steps:
+      - run: echo "${{ github.sha }} ${{ github.event.issue.number }}"
+`,
+			wantRules: nil,
+			wantLines: nil,
+		},
+		{
+			name: "script injection in github-script",
+			text: `on: issue_comment
+jobs:
+  comment:
+    steps:
+      - uses: actions/github-script@11bd71901bbe5b1630ceea73d27597364c9af683
+        with:
+          script: |
+            const body = "${{ github.event.comment.body }}"
+`,
+			wantRules: []string{findingScriptInjection},
+			wantLines: []int{8},
+		},
+		{
+			name: "pull_request_target checks out head sha",
+			text: `on: pull_request_target
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+`,
+			wantRules: []string{findingUntrustedCheckout},
+			wantLines: []int{7},
+		},
+		{
+			name: "workflow_run checks out pull request with gh",
+			text: `on:
+  workflow_run:
+    workflows: [ci]
+jobs:
+  build:
+    steps:
+      - run: gh pr checkout ${{ github.event.workflow_run.pull_requests[0].number }}
+`,
+			wantRules: []string{findingUntrustedCheckout},
+			wantLines: []int{7},
+		},
+		{
+			name: "pull_request checks out head sha",
+			text: `on: pull_request
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+`,
+			wantRules: nil,
+			wantLines: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWorkflow(tt.text)
+			if err != nil {
+				t.Fatalf("parseWorkflow() error = %v", err)
+			}
+
+			findings := scanWorkflow(root, tt.text)
+
+			if len(findings) != len(tt.wantRules) {
+				t.Fatalf("Expected %d findings, got %d: %v", len(tt.wantRules), len(findings), findings)
+			}
+			for i, f := range findings {
+				if f.Rule != tt.wantRules[i] {
+					t.Errorf("Expected rule %s, got %s", tt.wantRules[i], f.Rule)
+				}
+				if f.Line != tt.wantLines[i] {
+					t.Errorf("Expected line %d, got %d", tt.wantLines[i], f.Line)
+				}
+			}
+		})
+	}
+}
+
+func Test_isPinned(t *testing.T) {
+	tests := []struct {
+		uses string
+		want bool
+	}{
+		{uses: "actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683", want: true},
+		{uses: "actions/checkout@v4", want: false},
+		{uses: "actions/checkout@main", want: false},
+		{uses: "actions/checkout", want: false},
+		{uses: "octo-org/shared/.github/workflows/ci.yml@v1", want: false},
+		{uses: "./.github/actions/setup", want: true},
+		{uses: "docker://alpine:3.19", want: false},
+		{uses: "docker://alpine@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uses, func(t *testing.T) {
+			if got := isPinned(tt.uses); got != tt.want {
+				t.Errorf("isPinned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isPinned_Exclude(t *testing.T) {
+	// excluding GitHub authored actions from the report must not hide unpinned findings
+	exclude = true
+	defer func() { exclude = false }()
+
+	for _, uses := range []string{"actions/checkout@v4", "github/codeql-action/init@v3"} {
+		if isPinned(uses) {
+			t.Errorf("Expected %s to be unpinned with --exclude", uses)
+		}
+	}
+}
+
+func Test_scanWorkflow_Unpinned(t *testing.T) {
+	text := `on: push
+jobs:
+  shared:
+    uses: octo-org/shared/.github/workflows/ci.yml@main
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/setup
+`
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	findings := scanWorkflow(root, text)
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d: %v", len(findings), findings)
+	}
+
+	for i, line := range []int{4, 7} {
+		if findings[i].Rule != findingUnpinnedAction {
+			t.Errorf("Expected rule %s, got %s", findingUnpinnedAction, findings[i].Rule)
+		}
+		if findings[i].Line != line {
+			t.Errorf("Expected line %d, got %d", line, findings[i].Line)
+		}
+	}
+}
+
+func Test_locate(t *testing.T) {
+	text := "jobs:\n  build:\n    steps:\n      - run: |\n          echo ok\n          echo ${{ github.head_ref }}\n"
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	findings := scanWorkflow(root, text)
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d", len(findings))
+	}
+
+	if findings[0].Line != 6 || findings[0].Column != 16 {
+		t.Errorf("Expected line 6 column 16, got line %d column %d", findings[0].Line, findings[0].Column)
+	}
+}
+
+func Test_locate_Span(t *testing.T) {
+	text := `jobs:
+  build:
+    steps:
+      - run: |
+          echo ${{ github.head_ref }}
+          echo ${{ github.head_ref }}
+        shell: bash
+      - run: echo ${{ github.event.issue.title }}
+        env:
+          TITLE: ${{ github.event.issue.title }}
+`
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	findings := scanWorkflow(root, text)
+	if len(findings) != 3 {
+		t.Fatalf("Expected 3 findings, got %d", len(findings))
+	}
+
+	// repeated expressions are located one by one
+	if findings[0].Line != 5 || findings[1].Line != 6 {
+		t.Errorf("Expected lines 5 and 6, got %d and %d", findings[0].Line, findings[1].Line)
+	}
+
+	// the expression of the plain scalar is not attributed to the env line
+	if findings[2].Line != 8 || findings[2].Column != 19 {
+		t.Errorf("Expected line 8 column 19, got line %d column %d", findings[2].Line, findings[2].Column)
+	}
+
+	// needles outside the span of the node fall back to its position
+	run := mappingValue(mappingValue(mappingValue(root, "jobs"), "build"), "steps").Content[1]
+	if line, col := locate(strings.Split(text, "\n"), mappingValue(run, "run"), "TITLE", 0); line != 8 || col != 14 {
+		t.Errorf("Expected line 8 column 14, got line %d column %d", line, col)
+	}
+}
+
+func Test_nodeSpan(t *testing.T) {
+	text := "steps:\n  - run: |\n      echo a\n\n      echo b\n    shell: bash\n"
+	lines := strings.Split(text, "\n")
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	run := mappingValue(mappingValue(root, "steps").Content[0], "run")
+
+	start, end := nodeSpan(lines, run)
+	if start != 1 || end != 5 {
+		t.Errorf("Expected span 1-5, got %d-%d", start, end)
+	}
+}
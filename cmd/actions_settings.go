@@ -0,0 +1,310 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	ActionsSettingsCmd = &cobra.Command{
+		Use:   "actions-settings",
+		Short: "Report on GitHub Actions organization settings",
+		Long: heredoc.Docf(
+			`Report on GitHub Actions organization settings, requires %[1]sadmin:org%[1]s and/or %[1]sread:enterprise%[1]s scope`,
+			"`",
+		),
+		RunE: GetActionsSettings,
+	}
+
+	actionsSettingsReport utils.CSVReport
+
+	mdActionsSettingsReport = `# GitHub Actions Settings Report
+
+| Organization | Enabled Repositories | Allowed Actions | GitHub Owned Allowed | Verified Allowed | Patterns Allowed | Default Workflow Permissions | Can Approve Pull Requests | Fork PR Approval |
+| ------------ | -------------------- | --------------- | -------------------- | ---------------- | ---------------- | ---------------------------- | ------------------------- | ---------------- |
+{{ range . }}| {{ .Organization }}{{ if .Error }}<br/>:warning: {{ .Error }}{{ end }} | {{ .EnabledRepositories }}{{ if eq .EnabledRepositories "selected" }} ({{ len .SelectedRepositories }}){{ end }} | {{ .AllowedActions }} | {{ if eq .AllowedActions "selected" }}` + "`" + `{{ .GitHubOwnedAllowed }}` + "`" + `{{ end }} | {{ if eq .AllowedActions "selected" }}` + "`" + `{{ .VerifiedAllowed }}` + "`" + `{{ end }} | {{ range $i, $v := .PatternsAllowed }}{{ if $i }}<br/>{{ end }}` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ .DefaultWorkflowPermissions }} | ` + "`" + `{{ .CanApprovePullRequestReviews }}` + "`" + ` | {{ .ForkPRApproval }} |
+{{ end }}
+`
+)
+
+type (
+	ActionsSettings struct {
+		Organization                 string   `json:"organization"`
+		EnabledRepositories          string   `json:"enabled_repositories"`
+		SelectedRepositories         []string `json:"selected_repositories,omitempty"`
+		AllowedActions               string   `json:"allowed_actions"`
+		GitHubOwnedAllowed           bool     `json:"github_owned_allowed"`
+		VerifiedAllowed              bool     `json:"verified_allowed"`
+		PatternsAllowed              []string `json:"patterns_allowed,omitempty"`
+		DefaultWorkflowPermissions   string   `json:"default_workflow_permissions"`
+		CanApprovePullRequestReviews bool     `json:"can_approve_pull_request_reviews"`
+		ForkPRApproval               string   `json:"fork_pr_approval,omitempty"`
+		Error                        string   `json:"error,omitempty"`
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(ActionsSettingsCmd)
+}
+
+// GetActionsSettings returns the GitHub Actions settings of every organization
+func GetActionsSettings(cmd *cobra.Command, args []string) (err error) {
+	if repo != "" {
+		return fmt.Errorf("Repository not supported for this report")
+	}
+
+	if user.Type == "User" {
+		return fmt.Errorf("%s not supported for this report", user.Type)
+	}
+
+	sp.Start()
+
+	orgs, err := listOrganizations()
+	if err != nil {
+		sp.Stop()
+		return err
+	}
+	organizations = append(organizations, orgs...)
+
+	var res []ActionsSettings
+
+	for _, org := range organizations {
+		sp.Suffix = fmt.Sprintf(
+			" fetching actions settings report %s",
+			utils.Cyan(org.Login),
+		)
+
+		s, err := fetchActionsSettings(org.Login)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+
+		res = append(res, s)
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	header := []string{
+		"organization",
+		"enabled_repositories",
+		"selected_repositories",
+		"allowed_actions",
+		"github_owned_allowed",
+		"verified_allowed",
+		"patterns_allowed",
+		"default_workflow_permissions",
+		"can_approve_pull_requests",
+		"fork_pr_approval",
+		"error",
+	}
+
+	var td = pterm.TableData{header}
+
+	// start CSV file
+	if csvPath != "" {
+		actionsSettingsReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		actionsSettingsReport.SetHeader(header)
+	}
+
+	for _, s := range res {
+		data := actionsSettingsRow(s)
+
+		td = append(td, data)
+
+		if csvPath != "" {
+			actionsSettingsReport.AddData(data)
+		}
+	}
+
+	if !silent {
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		actionsSettingsReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdActionsSettingsReport, res)
+	}
+
+	return err
+}
+
+// fetchActionsSettings returns the GitHub Actions permissions of an organization.
+// Organizations or settings that are not accessible are recorded instead of failing the report.
+func fetchActionsSettings(org string) (ActionsSettings, error) {
+	s := ActionsSettings{Organization: org}
+
+	var permissions struct {
+		EnabledRepositories string `json:"enabled_repositories"`
+		AllowedActions      string `json:"allowed_actions"`
+	}
+
+	if ok, err := getActionsSetting(fmt.Sprintf("orgs/%s/actions/permissions", org), &permissions); err != nil {
+		return s, err
+	} else if !ok {
+		s.Error = "actions permissions not accessible"
+		return s, nil
+	}
+
+	s.EnabledRepositories = permissions.EnabledRepositories
+	s.AllowedActions = permissions.AllowedActions
+
+	if s.EnabledRepositories == "selected" {
+		for page := 1; ; page++ {
+			var selected struct {
+				TotalCount   int `json:"total_count"`
+				Repositories []struct {
+					Name string `json:"name"`
+				} `json:"repositories"`
+			}
+
+			ok, err := getActionsSetting(
+				fmt.Sprintf("orgs/%s/actions/permissions/repositories?per_page=100&page=%d", org, page),
+				&selected,
+			)
+			if err != nil {
+				return s, err
+			}
+
+			if !ok {
+				break
+			}
+
+			for _, r := range selected.Repositories {
+				s.SelectedRepositories = append(s.SelectedRepositories, r.Name)
+			}
+
+			if len(selected.Repositories) < 100 || len(s.SelectedRepositories) >= selected.TotalCount {
+				break
+			}
+		}
+	}
+
+	if s.AllowedActions == "selected" {
+		var actions struct {
+			GitHubOwnedAllowed bool     `json:"github_owned_allowed"`
+			VerifiedAllowed    bool     `json:"verified_allowed"`
+			PatternsAllowed    []string `json:"patterns_allowed"`
+		}
+
+		if ok, err := getActionsSetting(fmt.Sprintf("orgs/%s/actions/permissions/selected-actions", org), &actions); err != nil {
+			return s, err
+		} else if ok {
+			s.GitHubOwnedAllowed = actions.GitHubOwnedAllowed
+			s.VerifiedAllowed = actions.VerifiedAllowed
+			s.PatternsAllowed = actions.PatternsAllowed
+		}
+	}
+
+	var workflow struct {
+		DefaultWorkflowPermissions   string `json:"default_workflow_permissions"`
+		CanApprovePullRequestReviews bool   `json:"can_approve_pull_request_reviews"`
+	}
+
+	if ok, err := getActionsSetting(fmt.Sprintf("orgs/%s/actions/permissions/workflow", org), &workflow); err != nil {
+		return s, err
+	} else if ok {
+		s.DefaultWorkflowPermissions = workflow.DefaultWorkflowPermissions
+		s.CanApprovePullRequestReviews = workflow.CanApprovePullRequestReviews
+	}
+
+	var approval struct {
+		ApprovalPolicy string `json:"approval_policy"`
+	}
+
+	if ok, err := getActionsSetting(fmt.Sprintf("orgs/%s/actions/permissions/fork-pr-contributor-approval", org), &approval); err != nil {
+		return s, err
+	} else if ok {
+		s.ForkPRApproval = approval.ApprovalPolicy
+	}
+
+	return s, nil
+}
+
+// getActionsSetting fetches a settings endpoint, it returns false if the setting is not accessible
+func getActionsSetting(endpoint string, v interface{}) (bool, error) {
+	if err := restClient.Get(endpoint, v); err != nil {
+		if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// actionsSettingsRow returns the table row of the settings of an organization
+func actionsSettingsRow(s ActionsSettings) []string {
+	var selected, githubOwned, verified string
+
+	if s.EnabledRepositories == "selected" {
+		selected = fmt.Sprintf("%d", len(s.SelectedRepositories))
+	}
+
+	if s.AllowedActions == "selected" {
+		githubOwned = fmt.Sprintf("%t", s.GitHubOwnedAllowed)
+		verified = fmt.Sprintf("%t", s.VerifiedAllowed)
+	}
+
+	var approve string
+	if s.DefaultWorkflowPermissions != "" {
+		approve = fmt.Sprintf("%t", s.CanApprovePullRequestReviews)
+	}
+
+	return []string{
+		s.Organization,
+		s.EnabledRepositories,
+		selected,
+		s.AllowedActions,
+		githubOwned,
+		verified,
+		strings.Join(s.PatternsAllowed, ", "),
+		s.DefaultWorkflowPermissions,
+		approve,
+		s.ForkPRApproval,
+		s.Error,
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ActionsSettings(t *testing.T) {
+	t.Skip()
+}
+
+func Test_actionsSettingsRow(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings ActionsSettings
+		want     []string
+	}{
+		{
+			name: "selected actions and repositories",
+			settings: ActionsSettings{
+				Organization:                 "octo-org",
+				EnabledRepositories:          "selected",
+				SelectedRepositories:         []string{"a", "b"},
+				AllowedActions:               "selected",
+				GitHubOwnedAllowed:           true,
+				PatternsAllowed:              []string{"octo-org/*", "docker/*"},
+				DefaultWorkflowPermissions:   "read",
+				CanApprovePullRequestReviews: false,
+				ForkPRApproval:               "first_time_contributors",
+			},
+			want: []string{"octo-org", "selected", "2", "selected", "true", "false", "octo-org/*, docker/*", "read", "false", "first_time_contributors", ""},
+		},
+		{
+			name: "all actions",
+			settings: ActionsSettings{
+				Organization:                 "octo-org",
+				EnabledRepositories:          "all",
+				AllowedActions:               "all",
+				DefaultWorkflowPermissions:   "write",
+				CanApprovePullRequestReviews: true,
+			},
+			want: []string{"octo-org", "all", "", "all", "", "", "", "write", "true", "", ""},
+		},
+		{
+			name:     "not accessible",
+			settings: ActionsSettings{Organization: "octo-org", Error: "actions permissions not accessible"},
+			want:     []string{"octo-org", "", "", "", "", "", "", "", "", "", "actions permissions not accessible"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := actionsSettingsRow(tt.settings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
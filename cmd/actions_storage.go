@@ -0,0 +1,393 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	ActionsStorageCmd = &cobra.Command{
+		Use:   "actions-storage",
+		Short: "Report on GitHub Actions cache and artifact storage",
+		Long: heredoc.Docf(
+			`Report on GitHub Actions cache and artifact storage per repository, requires %[1]srepo%[1]s and/or %[1]sread:org%[1]s scope`,
+			"`",
+		),
+		RunE: GetActionsStorage,
+	}
+
+	listArtifacts = false
+
+	actionsStorageReport utils.CSVReport
+
+	mdActionsStorageReport = `# GitHub Actions Storage Report
+
+| Owner | Repo | Cache Entries | Cache Size (MB) | Artifacts | Artifacts Size (MB) | Total Size (MB) |
+| ----- | ---- | ------------: | --------------: | --------: | ------------------: | --------------: |
+{{ range . }}| {{ .Owner }} | {{ .Repo }} | {{ .CacheEntries }} | {{ .CacheSizeMB }} | {{ .ArtifactCount }} | {{ .ArtifactsSizeMB }} | {{ .TotalSizeMB }} |
+{{ end }}
+## Artifacts
+
+| Owner | Repo | Name | Size (MB) | Expired | Expires At | Workflow Run | Branch |
+| ----- | ---- | ---- | --------: | ------- | ---------- | -----------: | ------ |
+{{ range . }}{{ $owner := .Owner }}{{ $repo := .Repo }}{{ range .Artifacts }}| {{ $owner }} | {{ $repo }} | {{ .Name }} | {{ .SizeMB }} | {{ .Expired }} | {{ .ExpiresAt.UTC.Format "2006-01-02 15:04:05 MST" }} | {{ .WorkflowRunID }} | {{ .Branch }} |
+{{ end }}{{ end }}
+`
+)
+
+type (
+	ArtifactData struct {
+		Name        string    `json:"name"`
+		SizeInBytes int64     `json:"size_in_bytes"`
+		Expired     bool      `json:"expired"`
+		ExpiresAt   time.Time `json:"expires_at"`
+		WorkflowRun struct {
+			ID         int64  `json:"id"`
+			HeadBranch string `json:"head_branch"`
+		} `json:"workflow_run"`
+	}
+
+	CacheUsageData struct {
+		FullName                string `json:"full_name"`
+		ActiveCachesSizeInBytes int64  `json:"active_caches_size_in_bytes"`
+		ActiveCachesCount       int    `json:"active_caches_count"`
+	}
+
+	ActionsArtifact struct {
+		Name          string    `json:"name"`
+		Size          int64     `json:"size_in_bytes"`
+		Expired       bool      `json:"expired"`
+		ExpiresAt     time.Time `json:"expires_at"`
+		WorkflowRunID int64     `json:"workflow_run_id"`
+		Branch        string    `json:"branch,omitempty"`
+	}
+
+	ActionsStorage struct {
+		Owner         string            `json:"owner"`
+		Repo          string            `json:"repo"`
+		CacheEntries  int               `json:"cache_entries"`
+		CacheSize     int64             `json:"cache_size_in_bytes"`
+		ArtifactCount int               `json:"artifact_count"`
+		ArtifactsSize int64             `json:"artifacts_size_in_bytes"`
+		TotalSize     int64             `json:"total_size_in_bytes"`
+		Artifacts     []ActionsArtifact `json:"artifacts"`
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(ActionsStorageCmd)
+
+	ActionsStorageCmd.Flags().BoolVar(&listArtifacts, "artifacts", false, "List every artifact instead of the totals per repository")
+}
+
+// GetActionsStorage returns the Actions cache and artifact storage of every repository
+func GetActionsStorage(cmd *cobra.Command, args []string) (err error) {
+	sp.Start()
+
+	var targets []RESTRepository
+	caches := map[string]CacheUsageData{}
+
+	if repo != "" {
+		targets = append(targets, RESTRepository{Name: repo, FullName: fmt.Sprintf("%s/%s", owner, repo)})
+	} else {
+		orgs, err := listOrganizations()
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		organizations = append(organizations, orgs...)
+
+		for _, org := range organizations {
+			sp.Suffix = fmt.Sprintf(
+				" fetching actions storage report %s %s",
+				utils.Cyan(org.Login),
+				utils.HiBlack("(repositories)"),
+			)
+
+			isUser := org.Login == owner && user.Type == "User"
+
+			repos, err := listRepositories(org.Login, isUser)
+			if err != nil {
+				sp.Stop()
+				return err
+			}
+
+			targets = append(targets, repos...)
+
+			// organizations report the cache usage of all repositories at once
+			if !isUser {
+				if err := fetchOrgCacheUsage(org.Login, caches); err != nil {
+					sp.Stop()
+					return err
+				}
+			}
+		}
+	}
+
+	var res []ActionsStorage
+
+	for _, r := range targets {
+		sp.Suffix = fmt.Sprintf(
+			" fetching actions storage report %s",
+			utils.Cyan(r.FullName),
+		)
+
+		if _, ok := caches[r.FullName]; !ok && (repo != "" || user.Type == "User") {
+			var usage CacheUsageData
+
+			if err := restClient.Get(fmt.Sprintf("repos/%s/actions/cache/usage", r.FullName), &usage); err != nil {
+				if !strings.Contains(err.Error(), "403") && !strings.Contains(err.Error(), "404") {
+					sp.Stop()
+					return err
+				}
+			}
+
+			caches[r.FullName] = usage
+		}
+
+		artifacts, err := fetchArtifacts(r.FullName)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+
+		o, n, _ := strings.Cut(r.FullName, "/")
+		res = append(res, actionsStorage(o, n, caches[r.FullName], artifacts))
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	sortActionsStorage(res)
+
+	var header []string
+	var rows [][]string
+
+	if listArtifacts {
+		header = []string{"owner", "repo", "name", "size_mb", "expired", "expires_at", "workflow_run", "branch"}
+
+		for _, s := range res {
+			for _, a := range s.Artifacts {
+				rows = append(rows, []string{
+					s.Owner,
+					s.Repo,
+					a.Name,
+					megabytes(a.Size),
+					fmt.Sprintf("%t", a.Expired),
+					a.ExpiresAt.UTC().Format("2006-01-02 15:04:05 MST"),
+					fmt.Sprintf("%d", a.WorkflowRunID),
+					a.Branch,
+				})
+			}
+		}
+	} else {
+		header = []string{"owner", "repo", "cache_entries", "cache_size_mb", "artifacts", "artifacts_size_mb", "total_size_mb"}
+
+		for _, s := range res {
+			rows = append(rows, []string{
+				s.Owner,
+				s.Repo,
+				fmt.Sprintf("%d", s.CacheEntries),
+				megabytes(s.CacheSize),
+				fmt.Sprintf("%d", s.ArtifactCount),
+				megabytes(s.ArtifactsSize),
+				megabytes(s.TotalSize),
+			})
+		}
+	}
+
+	var td = pterm.TableData{header}
+
+	// start CSV file
+	if csvPath != "" {
+		actionsStorageReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		actionsStorageReport.SetHeader(header)
+	}
+
+	for _, data := range rows {
+		td = append(td, data)
+
+		if csvPath != "" {
+			actionsStorageReport.AddData(data)
+		}
+	}
+
+	if !silent {
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		actionsStorageReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdActionsStorageReport, res)
+	}
+
+	return err
+}
+
+// fetchOrgCacheUsage adds the Actions cache usage of every repository of an organization to caches
+func fetchOrgCacheUsage(org string, caches map[string]CacheUsageData) error {
+	var count int
+
+	for page := 1; ; page++ {
+		var res struct {
+			TotalCount            int              `json:"total_count"`
+			RepositoryCacheUsages []CacheUsageData `json:"repository_cache_usages"`
+		}
+
+		if err := restClient.Get(
+			fmt.Sprintf("orgs/%s/actions/cache/usage-by-repository?per_page=100&page=%d", org, page),
+			&res,
+		); err != nil {
+			if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, c := range res.RepositoryCacheUsages {
+			caches[c.FullName] = c
+		}
+
+		count += len(res.RepositoryCacheUsages)
+
+		if len(res.RepositoryCacheUsages) < 100 || count >= res.TotalCount {
+			break
+		}
+	}
+
+	return nil
+}
+
+// fetchArtifacts returns the artifacts of a repository
+func fetchArtifacts(nameWithOwner string) ([]ArtifactData, error) {
+	var artifacts []ArtifactData
+
+	for page := 1; ; page++ {
+		var res struct {
+			TotalCount int            `json:"total_count"`
+			Artifacts  []ArtifactData `json:"artifacts"`
+		}
+
+		if err := restClient.Get(
+			fmt.Sprintf("repos/%s/actions/artifacts?per_page=100&page=%d", nameWithOwner, page),
+			&res,
+		); err != nil {
+			if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+				return artifacts, nil
+			}
+
+			return nil, err
+		}
+
+		artifacts = append(artifacts, res.Artifacts...)
+
+		if len(res.Artifacts) < 100 || len(artifacts) >= res.TotalCount {
+			break
+		}
+	}
+
+	return artifacts, nil
+}
+
+// actionsStorage sums the cache and artifact storage of a repository.
+// Expired artifacts are listed but do not count towards the storage used.
+func actionsStorage(owner, repo string, cache CacheUsageData, artifacts []ArtifactData) ActionsStorage {
+	s := ActionsStorage{
+		Owner:        owner,
+		Repo:         repo,
+		CacheEntries: cache.ActiveCachesCount,
+		CacheSize:    cache.ActiveCachesSizeInBytes,
+		Artifacts:    []ActionsArtifact{},
+	}
+
+	for _, a := range artifacts {
+		s.Artifacts = append(s.Artifacts, ActionsArtifact{
+			Name:          a.Name,
+			Size:          a.SizeInBytes,
+			Expired:       a.Expired,
+			ExpiresAt:     a.ExpiresAt,
+			WorkflowRunID: a.WorkflowRun.ID,
+			Branch:        a.WorkflowRun.HeadBranch,
+		})
+
+		if !a.Expired {
+			s.ArtifactCount++
+			s.ArtifactsSize += a.SizeInBytes
+		}
+	}
+
+	s.TotalSize = s.CacheSize + s.ArtifactsSize
+
+	return s
+}
+
+// sortActionsStorage sorts repositories by the storage they use, largest first
+func sortActionsStorage(res []ActionsStorage) {
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].TotalSize > res[j].TotalSize
+	})
+}
+
+func (s ActionsStorage) CacheSizeMB() string {
+	return megabytes(s.CacheSize)
+}
+
+func (s ActionsStorage) ArtifactsSizeMB() string {
+	return megabytes(s.ArtifactsSize)
+}
+
+func (s ActionsStorage) TotalSizeMB() string {
+	return megabytes(s.TotalSize)
+}
+
+func (a ActionsArtifact) SizeMB() string {
+	return megabytes(a.Size)
+}
+
+// megabytes formats a size in bytes as megabytes
+func megabytes(b int64) string {
+	return fmt.Sprintf("%.2f", float64(b)/1024/1024)
+}
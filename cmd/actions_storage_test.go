@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func Test_ActionsStorage(t *testing.T) {
+	t.Skip()
+}
+
+func Test_actionsStorage(t *testing.T) {
+	expires := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	artifacts := []ArtifactData{
+		{Name: "dist", SizeInBytes: 2 * 1024 * 1024, ExpiresAt: expires},
+		{Name: "coverage", SizeInBytes: 1024 * 1024, ExpiresAt: expires},
+		{Name: "old", SizeInBytes: 10 * 1024 * 1024, Expired: true, ExpiresAt: expires},
+	}
+	artifacts[0].WorkflowRun.ID = 42
+	artifacts[0].WorkflowRun.HeadBranch = "main"
+
+	got := actionsStorage("octo-org", "octo-repo", CacheUsageData{ActiveCachesCount: 3, ActiveCachesSizeInBytes: 5 * 1024 * 1024}, artifacts)
+
+	if got.CacheEntries != 3 || got.CacheSize != 5*1024*1024 {
+		t.Errorf("Expected 3 cache entries of 5MB, got %d of %d", got.CacheEntries, got.CacheSize)
+	}
+
+	if got.ArtifactCount != 2 || got.ArtifactsSize != 3*1024*1024 {
+		t.Errorf("Expected 2 artifacts of 3MB, got %d of %d", got.ArtifactCount, got.ArtifactsSize)
+	}
+
+	if got.TotalSizeMB() != "8.00" {
+		t.Errorf("Expected 8.00 MB total, got %s", got.TotalSizeMB())
+	}
+
+	if len(got.Artifacts) != 3 || got.Artifacts[0].WorkflowRunID != 42 || got.Artifacts[0].Branch != "main" {
+		t.Errorf("Expected all 3 artifacts listed with their workflow run, got %+v", got.Artifacts)
+	}
+}
+
+func Test_sortActionsStorage(t *testing.T) {
+	res := []ActionsStorage{
+		{Repo: "small", TotalSize: 1},
+		{Repo: "large", TotalSize: 100},
+		{Repo: "medium", TotalSize: 10},
+	}
+
+	sortActionsStorage(res)
+
+	if res[0].Repo != "large" || res[1].Repo != "medium" || res[2].Repo != "small" {
+		t.Errorf("Expected large, medium, small, got %v", res)
+	}
+}
+
+func Test_mdActionsStorageReport(t *testing.T) {
+	res := []ActionsStorage{
+		actionsStorage("octo-org", "octo-repo", CacheUsageData{ActiveCachesCount: 1, ActiveCachesSizeInBytes: 1024 * 1024}, []ArtifactData{
+			{Name: "dist", SizeInBytes: 512 * 1024},
+		}),
+	}
+
+	var b bytes.Buffer
+	if err := template.Must(template.New("report").Parse(mdActionsStorageReport)).Execute(&b, res); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !strings.Contains(b.String(), "| octo-org | octo-repo | 1 | 1.00 | 1 | 0.50 | 1.50 |") {
+		t.Errorf("Expected repository row, got %s", b.String())
+	}
+
+	if !strings.Contains(b.String(), "| octo-org | octo-repo | dist | 0.50 |") {
+		t.Errorf("Expected artifact row, got %s", b.String())
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	mdActionsSummaryTemplate = `# GitHub Actions Usage Summary
+
+| Action | Category | Repositories | Workflows | Uses | Versions |
+| ------ | -------- | -----------: | --------: | ---: | -------- |
+{{ range . }}| {{ .Action }} | {{ .Category }} | {{ .Repositories }} | {{ .Workflows }} | {{ .Uses }} | {{ range $i, $v := .Versions }}{{ if $i }}<br/>{{ end }}` + "`" + `{{ $v.Ref }}` + "`" + ` ({{ $v.Count }}){{ end }} |
+{{ end }}
+`
+)
+
+type (
+	ActionUsageSummary struct {
+		Action       string          `json:"action"`
+		Category     string          `json:"category"`
+		Repositories int             `json:"repositories"`
+		Workflows    int             `json:"workflows"`
+		Uses         int             `json:"uses"`
+		Versions     []ActionVersion `json:"versions"`
+	}
+
+	ActionVersion struct {
+		Ref   string `json:"ref"`
+		Count int    `json:"count"`
+	}
+)
+
+// actionCategory classifies an action used in a repository of owner as
+// first-party (GitHub authored), internal (same owner or local), docker, or marketplace
+func actionCategory(action, owner string) string {
+	switch {
+	case strings.HasPrefix(action, "./"):
+		return "internal"
+	case strings.HasPrefix(action, "docker://"):
+		return "docker"
+	case strings.HasPrefix(action, "actions/") || strings.HasPrefix(action, "github/"):
+		return "first-party"
+	case strings.EqualFold(strings.Split(action, "/")[0], owner):
+		return "internal"
+	default:
+		return "marketplace"
+	}
+}
+
+// summarizeActions counts the repositories, workflows and versions of every distinct action,
+// sorted by the number of repositories using it. top limits the result if greater than 0.
+func summarizeActions(res []ActionUsesReport, top int) []ActionUsageSummary {
+	type usage struct {
+		summary   ActionUsageSummary
+		repos     map[string]bool
+		workflows map[string]bool
+		versions  map[string]int
+	}
+
+	actions := map[string]*usage{}
+
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			for _, s := range w.UsesSteps {
+				if !excludeGitHubAuthored(s.Action) {
+					continue
+				}
+
+				name := s.Action
+				// local actions are distinct per repository
+				if strings.HasPrefix(name, "./") {
+					name = fmt.Sprintf("%s/%s/%s", r.Owner, r.Repo, strings.TrimPrefix(name, "./"))
+				}
+
+				key := strings.ToLower(name)
+
+				u, ok := actions[key]
+				if !ok {
+					u = &usage{
+						summary: ActionUsageSummary{
+							Action:   name,
+							Category: actionCategory(s.Action, r.Owner),
+						},
+						repos:     map[string]bool{},
+						workflows: map[string]bool{},
+						versions:  map[string]int{},
+					}
+					actions[key] = u
+				}
+
+				u.summary.Uses++
+				u.repos[r.Owner+"/"+r.Repo] = true
+				u.workflows[workflowRef(r.Owner+"/"+r.Repo+"/"+w.Path, r.Branch)] = true
+				u.versions[s.Ref]++
+			}
+		}
+	}
+
+	summary := []ActionUsageSummary{}
+
+	for _, u := range actions {
+		u.summary.Repositories = len(u.repos)
+		u.summary.Workflows = len(u.workflows)
+
+		for ref, c := range u.versions {
+			u.summary.Versions = append(u.summary.Versions, ActionVersion{Ref: ref, Count: c})
+		}
+
+		sort.Slice(u.summary.Versions, func(i, j int) bool {
+			a, b := u.summary.Versions[i], u.summary.Versions[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+
+			return a.Ref < b.Ref
+		})
+
+		summary = append(summary, u.summary)
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		a, b := summary[i], summary[j]
+		if a.Repositories != b.Repositories {
+			return a.Repositories > b.Repositories
+		}
+		if a.Workflows != b.Workflows {
+			return a.Workflows > b.Workflows
+		}
+
+		return strings.ToLower(a.Action) < strings.ToLower(b.Action)
+	})
+
+	if top > 0 && len(summary) > top {
+		summary = summary[:top]
+	}
+
+	return summary
+}
+
+func versionsToString(v []ActionVersion) []string {
+	var s = []string{}
+
+	for _, e := range v {
+		ref := e.Ref
+		if ref == "" {
+			ref = "HEAD"
+		}
+
+		s = append(s, fmt.Sprintf("%s (%d)", ref, e.Count))
+	}
+
+	return s
+}
+
+// saveSummaryView outputs the aggregated usage of every distinct action
+func saveSummaryView(res []ActionUsesReport) error {
+	summary := summarizeActions(res, top)
+
+	var rows [][]string
+	for _, s := range summary {
+		rows = append(rows, []string{
+			s.Action,
+			s.Category,
+			fmt.Sprintf("%d", s.Repositories),
+			fmt.Sprintf("%d", s.Workflows),
+			fmt.Sprintf("%d", s.Uses),
+			strings.Join(versionsToString(s.Versions), ", "),
+		})
+	}
+
+	return saveActionsReport(
+		[]string{"action", "category", "repositories", "workflows", "uses", "versions"},
+		rows,
+		summary,
+		mdActionsSummaryTemplate,
+	)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_actionCategory(t *testing.T) {
+	tests := []struct {
+		action string
+		want   string
+	}{
+		{action: "actions/checkout", want: "first-party"},
+		{action: "github/codeql-action/analyze", want: "first-party"},
+		{action: "octo-org/shared-action", want: "internal"},
+		{action: "Octo-Org/shared/.github/workflows/ci.yml", want: "internal"},
+		{action: "./.github/actions/setup", want: "internal"},
+		{action: "docker://alpine:3.19", want: "docker"},
+		{action: "tj-actions/changed-files", want: "marketplace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			if got := actionCategory(tt.action, "octo-org"); got != tt.want {
+				t.Errorf("actionCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_summarizeActions(t *testing.T) {
+	res := []ActionUsesReport{
+		{
+			Owner: "octo-org",
+			Repo:  "a",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{
+						{Job: "build", Action: "actions/checkout", Ref: "v4"},
+						{Job: "lint", Action: "actions/checkout", Ref: "v4"},
+						{Job: "build", Action: "./.github/actions/setup"},
+					},
+				},
+				{
+					Path:      ".github/workflows/release.yml",
+					UsesSteps: []WorkflowUsesStep{{Job: "release", Action: "actions/checkout", Ref: "v3"}},
+				},
+			},
+		},
+		{
+			Owner: "octo-org",
+			Repo:  "b",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{
+						{Job: "build", Action: "actions/checkout", Ref: "v4"},
+						{Job: "build", Action: "tj-actions/changed-files", Ref: "v40"},
+					},
+				},
+			},
+		},
+	}
+
+	got := summarizeActions(res, 0)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 actions, got %d: %v", len(got), got)
+	}
+
+	checkout := got[0]
+	if checkout.Action != "actions/checkout" || checkout.Repositories != 2 || checkout.Workflows != 3 || checkout.Uses != 4 {
+		t.Errorf("Unexpected summary for actions/checkout: %+v", checkout)
+	}
+
+	wantVersions := []ActionVersion{{Ref: "v4", Count: 3}, {Ref: "v3", Count: 1}}
+	if !reflect.DeepEqual(checkout.Versions, wantVersions) {
+		t.Errorf("Expected versions %v, got %v", wantVersions, checkout.Versions)
+	}
+
+	if got[1].Action != "octo-org/a/.github/actions/setup" || got[1].Category != "internal" {
+		t.Errorf("Expected local action to be internal, got %+v", got[1])
+	}
+
+	if top := summarizeActions(res, 1); len(top) != 1 || top[0].Action != "actions/checkout" {
+		t.Errorf("Expected top 1 to be actions/checkout, got %v", top)
+	}
+}
+
+func Test_summarizeActions_Branches(t *testing.T) {
+	steps := []WorkflowUsesStep{{Job: "build", Action: "actions/checkout", Ref: "v4"}}
+	res := []ActionUsesReport{
+		{Owner: "octo-org", Repo: "a", Branch: "main", Workflows: []ActionWorkflow{{Path: ".github/workflows/ci.yml", UsesSteps: steps}}},
+		{Owner: "octo-org", Repo: "a", Branch: "dev", Workflows: []ActionWorkflow{{Path: ".github/workflows/ci.yml", UsesSteps: steps}}},
+	}
+
+	got := summarizeActions(res, 0)
+	if len(got) != 1 || got[0].Repositories != 1 || got[0].Workflows != 2 || got[0].Uses != 2 {
+		t.Errorf("Expected the workflow to be counted once per branch, got %+v", got)
+	}
+}
@@ -7,3 +7,87 @@ import (
 func Test_Actions(t *testing.T) {
 	t.Skip()
 }
+
+func Test_skipRepository(t *testing.T) {
+	tests := []struct {
+		name            string
+		repo            ActionUsesRepository
+		includeArchived bool
+		includeForks    bool
+		internal        bool
+		public          bool
+		want            bool
+	}{
+		{
+			name: "active repository",
+			repo: ActionUsesRepository{Visibility: "PRIVATE"},
+			want: false,
+		},
+		{
+			name: "archived repository",
+			repo: ActionUsesRepository{Visibility: "PRIVATE", IsArchived: true},
+			want: true,
+		},
+		{
+			name:            "archived repository included",
+			repo:            ActionUsesRepository{Visibility: "PRIVATE", IsArchived: true},
+			includeArchived: true,
+			want:            false,
+		},
+		{
+			name: "forked repository",
+			repo: ActionUsesRepository{Visibility: "PUBLIC", IsFork: true},
+			want: true,
+		},
+		{
+			name:         "forked repository included",
+			repo:         ActionUsesRepository{Visibility: "PUBLIC", IsFork: true},
+			includeForks: true,
+			want:         false,
+		},
+		{
+			name:         "archived fork with forks included",
+			repo:         ActionUsesRepository{Visibility: "PUBLIC", IsArchived: true, IsFork: true},
+			includeForks: true,
+			want:         true,
+		},
+		{
+			name:     "internal filter",
+			repo:     ActionUsesRepository{Visibility: "PRIVATE"},
+			internal: true,
+			want:     true,
+		},
+		{
+			name:   "public filter",
+			repo:   ActionUsesRepository{Visibility: "PUBLIC"},
+			public: true,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			includeArchived, includeForks = tt.includeArchived, tt.includeForks
+			internal, private, public = tt.internal, false, tt.public
+
+			defer func() {
+				includeArchived, includeForks = false, false
+				internal, private, public = false, false, false
+			}()
+
+			if got := skipRepository(tt.repo); got != tt.want {
+				t.Errorf("Expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_ActionsArgs(t *testing.T) {
+	// `--all-branches release/*` parses as `*` plus a positional argument
+	if err := ActionsCmd.Args(ActionsCmd, []string{"release/*"}); err == nil {
+		t.Errorf("Expected positional arguments to be rejected")
+	}
+	if err := ActionsCmd.Args(ActionsCmd, nil); err != nil {
+		t.Errorf("Expected no error without arguments, got %v", err)
+	}
+}
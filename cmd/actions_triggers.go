@@ -0,0 +1,473 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	cronMonths = []string{"", "Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	cronDays   = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+	mdActionsTriggersTemplate = `# GitHub Actions Triggers Report
+
+| Owner | Repo | Branch | Workflow | Event | Filters |
+| ----- | ---- | ------ | -------- | ----- | ------- |
+{{ range . }}| {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .Workflow }} | ` + "`" + `{{ .Event }}` + "`" + ` | {{ range $i, $v := .Filters }}{{ if $i }}<br/>{{ end }}{{ $v }}{{ end }} |
+{{ end }}
+`
+
+	mdActionsSchedulesTemplate = `# GitHub Actions Schedules Report
+
+| Owner | Repo | Branch | Workflow | Cron | Cadence | Runs per Month |
+| ----- | ---- | ------ | -------- | ---- | ------- | -------------: |
+{{ range . }}| {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .Workflow }} | ` + "`" + `{{ .Cron }}` + "`" + ` | {{ .Cadence }} | {{ .RunsPerMonth }} |
+{{ end }}
+`
+)
+
+type (
+	WorkflowTrigger struct {
+		Event   string   `json:"event"`
+		Filters []string `json:"filters,omitempty"`
+	}
+
+	WorkflowSchedule struct {
+		Cron         string `json:"cron"`
+		Cadence      string `json:"cadence"`
+		RunsPerMonth int    `json:"runs_per_month"`
+	}
+
+	ActionTriggerReport struct {
+		Owner    string   `json:"owner"`
+		Repo     string   `json:"repo"`
+		Branch   string   `json:"branch"`
+		Workflow string   `json:"workflow"`
+		Event    string   `json:"event"`
+		Filters  []string `json:"filters"`
+	}
+
+	ActionScheduleReport struct {
+		Owner        string `json:"owner"`
+		Repo         string `json:"repo"`
+		Branch       string `json:"branch"`
+		Workflow     string `json:"workflow"`
+		Cron         string `json:"cron"`
+		Cadence      string `json:"cadence"`
+		RunsPerMonth int    `json:"runs_per_month"`
+	}
+
+	// cronSchedule holds the matching values of each field of a cron expression
+	cronSchedule struct {
+		minutes []bool
+		hours   []bool
+		dom     []bool
+		months  []bool
+		dow     []bool
+		// fields as written, `*` means unrestricted
+		fields []string
+	}
+)
+
+// workflowTriggersWithFilters returns the events of the workflow's `on:` section with their filters,
+// e.g. branches, paths, types, or the cron expressions of a schedule
+func workflowTriggersWithFilters(root *yaml.Node) []WorkflowTrigger {
+	var triggers []WorkflowTrigger
+
+	on := mappingValue(root, "on")
+	if on == nil || on.Kind != yaml.MappingNode {
+		for _, t := range workflowTriggers(root) {
+			triggers = append(triggers, WorkflowTrigger{Event: t})
+		}
+
+		return triggers
+	}
+
+	mappingPairs(on, func(k, v *yaml.Node) {
+		t := WorkflowTrigger{Event: k.Value}
+
+		switch v.Kind {
+		case yaml.SequenceNode:
+			// schedule
+			for _, e := range v.Content {
+				if c := mappingValue(e, "cron"); c != nil {
+					t.Filters = append(t.Filters, fmt.Sprintf("cron: %s", c.Value))
+				}
+			}
+		case yaml.MappingNode:
+			mappingPairs(v, func(fk, fv *yaml.Node) {
+				t.Filters = append(t.Filters, fmt.Sprintf("%s: %s", fk.Value, strings.Join(nodeValues(fv), ", ")))
+			})
+		}
+
+		triggers = append(triggers, t)
+	})
+
+	return triggers
+}
+
+// nodeValues returns a scalar value, the values of a sequence, or the keys of a mapping
+func nodeValues(n *yaml.Node) []string {
+	var values []string
+
+	switch n.Kind {
+	case yaml.ScalarNode:
+		values = append(values, n.Value)
+	case yaml.SequenceNode:
+		for _, e := range n.Content {
+			values = append(values, resolveNode(e).Value)
+		}
+	case yaml.MappingNode:
+		mappingPairs(n, func(k, _ *yaml.Node) {
+			values = append(values, k.Value)
+		})
+	}
+
+	return values
+}
+
+// workflowSchedules returns the decoded cron schedules of the workflow
+func workflowSchedules(root *yaml.Node) []WorkflowSchedule {
+	var schedules []WorkflowSchedule
+
+	s := mappingValue(mappingValue(root, "on"), "schedule")
+	if s == nil || s.Kind != yaml.SequenceNode {
+		return schedules
+	}
+
+	for _, e := range s.Content {
+		c := mappingValue(e, "cron")
+		if c == nil {
+			continue
+		}
+
+		ws := WorkflowSchedule{Cron: c.Value}
+
+		cs, err := parseCron(c.Value)
+		if err != nil {
+			ws.Cadence = fmt.Sprintf("invalid cron expression: %s", err)
+		} else {
+			ws.Cadence = cs.describe()
+			ws.RunsPerMonth = cs.runsPerMonth()
+		}
+
+		schedules = append(schedules, ws)
+	}
+
+	return schedules
+}
+
+// parseCron parses a POSIX cron expression as supported by GitHub Actions
+func parseCron(expr string) (cronSchedule, error) {
+	var cs cronSchedule
+	var err error
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return cs, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	cs.fields = fields
+
+	if cs.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return cs, err
+	}
+	if cs.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return cs, err
+	}
+	if cs.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return cs, err
+	}
+	if cs.months, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return cs, err
+	}
+	// day of week allows 7 for Sunday
+	if cs.dow, err = parseCronField(fields[4], 0, 7, cronDays); err != nil {
+		return cs, err
+	}
+	if cs.dow[7] {
+		cs.dow[0] = true
+	}
+
+	return cs, nil
+}
+
+// parseCronField parses a single cron field, supporting `*`, lists, ranges, steps and names
+func parseCronField(field string, min, max int, names []string) ([]bool, error) {
+	set := make([]bool, max+1)
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+
+		if r, s, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", s)
+			}
+
+			part = r
+			step = n
+		}
+
+		lo, hi := min, max
+
+		if part != "*" {
+			l, h, isRange := strings.Cut(part, "-")
+
+			var err error
+			if lo, err = cronValue(l, names); err != nil {
+				return nil, err
+			}
+
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(h, names); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// e.g. 5/15 means 5 through max every 15
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("value out of range in %q", field)
+		}
+
+		for i := lo; i <= hi; i += step {
+			set[i] = true
+		}
+	}
+
+	return set, nil
+}
+
+func cronValue(s string, names []string) (int, error) {
+	for i, n := range names {
+		if n != "" && strings.EqualFold(n, s) {
+			return i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	return v, nil
+}
+
+// runsPerMonth estimates the number of runs per month, averaged over a non-leap year
+func (cs cronSchedule) runsPerMonth() int {
+	perDay := cronCount(cs.minutes) * cronCount(cs.hours)
+
+	var days int
+	for d := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() == 2025; d = d.AddDate(0, 0, 1) {
+		if cs.matchesDay(d) {
+			days++
+		}
+	}
+
+	return int(math.Round(float64(perDay*days) / 12))
+}
+
+// matchesDay follows cron semantics, if both day of month and day of week are restricted either can match
+func (cs cronSchedule) matchesDay(d time.Time) bool {
+	if !cs.months[int(d.Month())] {
+		return false
+	}
+
+	dom := cs.dom[d.Day()]
+	dow := cs.dow[int(d.Weekday())]
+
+	if restricted(cs.fields[2]) && restricted(cs.fields[4]) {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+// restricted reports whether a day of month or day of week field restricts the days a schedule runs on.
+// As in cron, fields starting with * (e.g. */2) are unrestricted, and days must match both fields.
+func restricted(field string) bool {
+	return !strings.HasPrefix(field, "*")
+}
+
+// describe returns a human-readable cadence of the schedule, all times are UTC
+func (cs cronSchedule) describe() string {
+	var parts []string
+
+	minutes := cronValues(cs.minutes)
+	hours := cronValues(cs.hours)
+
+	switch {
+	case cs.fields[0] == "*" && cs.fields[1] == "*":
+		parts = append(parts, "every minute")
+	case strings.HasPrefix(cs.fields[0], "*/") && cs.fields[1] == "*":
+		parts = append(parts, fmt.Sprintf("every %s minutes", strings.TrimPrefix(cs.fields[0], "*/")))
+	case len(minutes) == 1 && cs.fields[1] == "*":
+		parts = append(parts, fmt.Sprintf("hourly at minute %d", minutes[0]))
+	case len(minutes) == 1 && strings.HasPrefix(cs.fields[1], "*/"):
+		parts = append(parts, fmt.Sprintf("every %s hours at minute %d", strings.TrimPrefix(cs.fields[1], "*/"), minutes[0]))
+	case len(minutes)*len(hours) <= 4:
+		var times []string
+		for _, h := range hours {
+			for _, m := range minutes {
+				times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+			}
+		}
+		parts = append(parts, fmt.Sprintf("at %s UTC", strings.Join(times, ", ")))
+	default:
+		parts = append(parts, fmt.Sprintf("%d times a day", len(minutes)*len(hours)))
+	}
+
+	switch {
+	case restricted(cs.fields[2]) && restricted(cs.fields[4]):
+		parts = append(parts, fmt.Sprintf("on day %s of the month or on %s", joinInts(cronValues(cs.dom)), describeWeekdays(cs.dow)))
+	case cs.fields[2] == "*" && cs.fields[4] == "*":
+		parts = append(parts, "daily")
+	case cs.fields[2] == "*":
+		parts = append(parts, fmt.Sprintf("on %s", describeWeekdays(cs.dow)))
+	case cs.fields[4] == "*":
+		parts = append(parts, fmt.Sprintf("on day %s of the month", joinInts(cronValues(cs.dom))))
+	default:
+		parts = append(parts, fmt.Sprintf("on day %s of the month and on %s", joinInts(cronValues(cs.dom)), describeWeekdays(cs.dow)))
+	}
+
+	if cs.fields[3] != "*" {
+		var months []string
+		for _, m := range cronValues(cs.months) {
+			months = append(months, cronMonths[m])
+		}
+		parts = append(parts, fmt.Sprintf("in %s", strings.Join(months, ", ")))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func describeWeekdays(dow []bool) string {
+	var days []string
+	for _, d := range cronValues(dow[:7]) {
+		days = append(days, cronDays[d])
+	}
+
+	switch strings.Join(days, ",") {
+	case "Mon,Tue,Wed,Thu,Fri":
+		return "weekdays"
+	case "Sun,Sat":
+		return "weekends"
+	}
+
+	return strings.Join(days, ", ")
+}
+
+func cronValues(set []bool) []int {
+	var v []int
+	for i, ok := range set {
+		if ok {
+			v = append(v, i)
+		}
+	}
+
+	return v
+}
+
+func cronCount(set []bool) int {
+	return len(cronValues(set))
+}
+
+func joinInts(v []int) string {
+	var s []string
+	for _, i := range v {
+		s = append(s, strconv.Itoa(i))
+	}
+
+	return strings.Join(s, ", ")
+}
+
+// saveTriggersView outputs the events every workflow is triggered by
+func saveTriggersView(res []ActionUsesReport) error {
+	var triggers []ActionTriggerReport
+	var rows [][]string
+
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			for _, t := range w.Triggers {
+				triggers = append(triggers, ActionTriggerReport{
+					Owner:    r.Owner,
+					Repo:     r.Repo,
+					Branch:   r.Branch,
+					Workflow: w.Path,
+					Event:    t.Event,
+					Filters:  t.Filters,
+				})
+
+				rows = append(rows, []string{r.Owner, r.Repo, r.Branch, w.Path, t.Event, strings.Join(t.Filters, "; ")})
+			}
+		}
+	}
+
+	return saveActionsReport(
+		[]string{"owner", "repo", "branch", "workflow_path", "event", "filters"},
+		rows,
+		triggers,
+		mdActionsTriggersTemplate,
+	)
+}
+
+// saveSchedulesView outputs the cron schedules of every workflow with their cadence
+func saveSchedulesView(res []ActionUsesReport) error {
+	var schedules []ActionScheduleReport
+	var rows [][]string
+
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			for _, s := range w.Schedules {
+				schedules = append(schedules, ActionScheduleReport{
+					Owner:        r.Owner,
+					Repo:         r.Repo,
+					Branch:       r.Branch,
+					Workflow:     w.Path,
+					Cron:         s.Cron,
+					Cadence:      s.Cadence,
+					RunsPerMonth: s.RunsPerMonth,
+				})
+
+				rows = append(rows, []string{r.Owner, r.Repo, r.Branch, w.Path, s.Cron, s.Cadence, fmt.Sprintf("%d", s.RunsPerMonth)})
+			}
+		}
+	}
+
+	return saveActionsReport(
+		[]string{"owner", "repo", "branch", "workflow_path", "cron", "cadence", "runs_per_month"},
+		rows,
+		schedules,
+		mdActionsSchedulesTemplate,
+	)
+}
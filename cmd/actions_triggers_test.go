@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_workflowTriggersWithFilters(t *testing.T) {
+	text := `on:
+  push:
+    branches: [main, 'release/*']
+    paths-ignore:
+      - '*.md'
+  pull_request:
+    types: [opened, synchronize]
+  schedule:
+    - cron: '0 2 * * 1-5'
+  workflow_dispatch:
+`
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	want := []WorkflowTrigger{
+		{Event: "push", Filters: []string{"branches: main, release/*", "paths-ignore: *.md"}},
+		{Event: "pull_request", Filters: []string{"types: opened, synchronize"}},
+		{Event: "schedule", Filters: []string{"cron: 0 2 * * 1-5"}},
+		{Event: "workflow_dispatch"},
+	}
+
+	got := workflowTriggersWithFilters(root)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("workflowTriggersWithFilters() = %v, want %v", got, want)
+	}
+}
+
+func Test_workflowTriggersWithFilters_List(t *testing.T) {
+	root, err := parseWorkflow("on: [push, pull_request]\n")
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	want := []WorkflowTrigger{{Event: "push"}, {Event: "pull_request"}}
+
+	got := workflowTriggersWithFilters(root)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("workflowTriggersWithFilters() = %v, want %v", got, want)
+	}
+}
+
+func Test_parseCron(t *testing.T) {
+	tests := []struct {
+		cron         string
+		cadence      string
+		runsPerMonth int
+	}{
+		{cron: "* * * * *", cadence: "every minute, daily", runsPerMonth: 43800},
+		{cron: "*/15 * * * *", cadence: "every 15 minutes, daily", runsPerMonth: 2920},
+		{cron: "30 * * * *", cadence: "hourly at minute 30, daily", runsPerMonth: 730},
+		{cron: "0 */6 * * *", cadence: "every 6 hours at minute 0, daily", runsPerMonth: 122},
+		{cron: "0 2 * * *", cadence: "at 02:00 UTC, daily", runsPerMonth: 30},
+		{cron: "0 2 * * 1-5", cadence: "at 02:00 UTC, on weekdays", runsPerMonth: 22},
+		{cron: "0 0 * * SUN", cadence: "at 00:00 UTC, on Sun", runsPerMonth: 4},
+		{cron: "0 0 1 * *", cadence: "at 00:00 UTC, on day 1 of the month", runsPerMonth: 1},
+		{cron: "0 0 1 1,7 *", cadence: "at 00:00 UTC, on day 1 of the month, in Jan, Jul", runsPerMonth: 0},
+		{cron: "0 8,20 * * *", cadence: "at 08:00, 20:00 UTC, daily", runsPerMonth: 61},
+		{cron: "0 0 1 * 1", cadence: "at 00:00 UTC, on day 1 of the month or on Mon", runsPerMonth: 5},
+		{cron: "0 0 */2 * 1", cadence: "at 00:00 UTC, on day 1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31 of the month and on Mon", runsPerMonth: 2},
+		{cron: "0 0 1 * */2", cadence: "at 00:00 UTC, on day 1 of the month and on Sun, Tue, Thu, Sat", runsPerMonth: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cron, func(t *testing.T) {
+			cs, err := parseCron(tt.cron)
+			if err != nil {
+				t.Fatalf("parseCron() error = %v", err)
+			}
+
+			if got := cs.describe(); got != tt.cadence {
+				t.Errorf("describe() = %v, want %v", got, tt.cadence)
+			}
+			if got := cs.runsPerMonth(); got != tt.runsPerMonth {
+				t.Errorf("runsPerMonth() = %v, want %v", got, tt.runsPerMonth)
+			}
+		})
+	}
+}
+
+func Test_parseCron_Invalid(t *testing.T) {
+	for _, cron := range []string{"* * * *", "60 * * * *", "* * * * MON-", "*/0 * * * *", "5-1 * * * *"} {
+		t.Run(cron, func(t *testing.T) {
+			if _, err := parseCron(cron); err == nil {
+				t.Errorf("Expected error for %q", cron)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stoe/gh-report/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	versionConstraintRegex = regexp.MustCompile(`^(<=|>=|!=|<|>|=)\s*(.+)$`)
+
+	mdActionsUsesTemplate = `# GitHub Actions Uses Report
+
+Found **{{ .Summary.Uses }}** uses of ` + "`" + `{{ .Summary.Pattern }}` + "`" + ` in **{{ .Summary.Workflows }}** workflows across **{{ .Summary.Repositories }}** repositories.
+{{ if .Summary.Unresolved }}
+:warning: **{{ .Summary.Unresolved }}** refs are not versions and could not be compared to the constraint.
+{{ end }}
+| Owner | Repo | Branch | Workflow | Job | Step | Action | Ref |
+| ----- | ---- | ------ | -------- | --- | ---- | ------ | --- |
+{{ range .Uses }}| {{ .Owner }} | {{ .Repo }} | {{ .Branch }} | {{ .Workflow }} | {{ .Job }} | {{ .Step }} | {{ .Action }} | ` + "`" + `{{ .Ref }}` + "`" + `{{ if .Unresolved }} :warning: unresolved{{ end }} |
+{{ end }}
+`
+)
+
+type (
+	WorkflowUsesStep struct {
+		Job    string `json:"job"`
+		Step   string `json:"step,omitempty"`
+		Action string `json:"action"`
+		Ref    string `json:"ref,omitempty"`
+	}
+
+	ActionUsesMatch struct {
+		Owner    string `json:"owner"`
+		Repo     string `json:"repo"`
+		Branch   string `json:"branch"`
+		Workflow string `json:"workflow"`
+		Job      string `json:"job"`
+		Step     string `json:"step,omitempty"`
+		Action   string `json:"action"`
+		Ref      string `json:"ref,omitempty"`
+
+		// the ref is not a version and could not be compared to the version constraint
+		Unresolved bool `json:"unresolved,omitempty"`
+	}
+
+	ActionUsesSummary struct {
+		Pattern      string `json:"pattern"`
+		Uses         int    `json:"uses"`
+		Workflows    int    `json:"workflows"`
+		Repositories int    `json:"repositories"`
+		Unresolved   int    `json:"unresolved"`
+	}
+
+	ActionUsesMatchReport struct {
+		Summary ActionUsesSummary `json:"summary"`
+		Uses    []ActionUsesMatch `json:"uses"`
+	}
+)
+
+// workflowUsesSteps returns every action a step uses and every reusable workflow a job calls
+func workflowUsesSteps(root *yaml.Node) []WorkflowUsesStep {
+	var steps []WorkflowUsesStep
+
+	mappingPairs(mappingValue(root, "jobs"), func(k, v *yaml.Node) {
+		if uses := mappingValue(v, "uses"); uses != nil && uses.Value != "" {
+			action, ref := splitUses(uses.Value)
+			steps = append(steps, WorkflowUsesStep{Job: k.Value, Action: action, Ref: ref})
+		}
+	})
+
+	workflowSteps(root, func(job string, step *yaml.Node) {
+		if uses := mappingValue(step, "uses"); uses != nil && uses.Value != "" {
+			action, ref := splitUses(uses.Value)
+			steps = append(steps, WorkflowUsesStep{Job: job, Step: stepName(step), Action: action, Ref: ref})
+		}
+	})
+
+	return steps
+}
+
+// splitUses splits `owner/repo[/path]@ref` into the action and its ref
+func splitUses(uses string) (string, string) {
+	if i := strings.LastIndex(uses, "@"); i >= 0 && !strings.HasPrefix(uses, "docker://") {
+		return uses[:i], uses[i+1:]
+	}
+
+	return uses, ""
+}
+
+// matchUses reports whether an action and ref match the `--uses` pattern.
+// The pattern is a glob on the action or its owner/repo, optionally followed by
+// `@` and either a glob on the ref or a version constraint like `<v41` or `>=1.2`.
+// Refs that are not versions, e.g. branches or commit SHAs, cannot be compared to a
+// constraint, they match as unresolved so they are reported rather than dropped.
+func matchUses(pattern, action, ref string) (match, unresolved bool) {
+	p, constraint, hasConstraint := strings.Cut(pattern, "@")
+
+	p = strings.ToLower(p)
+	action = strings.ToLower(action)
+
+	ok, _ := path.Match(p, action)
+	if !ok {
+		// match owner/repo of actions in a subdirectory or reusable workflows
+		if parts := strings.SplitN(action, "/", 3); len(parts) == 3 {
+			ok, _ = path.Match(p, parts[0]+"/"+parts[1])
+		}
+	}
+
+	if !ok || !hasConstraint {
+		return ok, false
+	}
+
+	if m := versionConstraintRegex.FindStringSubmatch(constraint); m != nil {
+		c, ok := compareVersions(ref, m[2])
+		if !ok {
+			return true, true
+		}
+
+		switch m[1] {
+		case "<":
+			return c < 0, false
+		case "<=":
+			return c <= 0, false
+		case ">":
+			return c > 0, false
+		case ">=":
+			return c >= 0, false
+		case "!=":
+			return c != 0, false
+		default:
+			return c == 0, false
+		}
+	}
+
+	ok, _ = path.Match(constraint, ref)
+
+	return ok, false
+}
+
+// compareVersions compares two `v1.2.3` style versions, missing components count as 0.
+// It returns false if either is not a version.
+func compareVersions(a, b string) (int, bool) {
+	pa, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+
+	pb, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, true
+			}
+
+			return 1, true
+		}
+	}
+
+	return 0, true
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var p [3]int
+
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	if len(parts) > 3 {
+		return p, false
+	}
+
+	for i, s := range parts {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return p, false
+		}
+
+		p[i] = n
+	}
+
+	return p, true
+}
+
+// findUses returns every use of an action or reusable workflow matching pattern
+func findUses(res []ActionUsesReport, pattern string) ActionUsesMatchReport {
+	report := ActionUsesMatchReport{
+		Summary: ActionUsesSummary{Pattern: pattern},
+		Uses:    []ActionUsesMatch{},
+	}
+
+	repos := map[string]bool{}
+	workflows := map[string]bool{}
+
+	for _, r := range res {
+		for _, w := range r.Workflows {
+			for _, s := range w.UsesSteps {
+				match, unresolved := matchUses(pattern, s.Action, s.Ref)
+				if !match {
+					continue
+				}
+
+				if unresolved {
+					report.Summary.Unresolved++
+				}
+
+				report.Uses = append(report.Uses, ActionUsesMatch{
+					Owner:      r.Owner,
+					Repo:       r.Repo,
+					Branch:     r.Branch,
+					Workflow:   w.Path,
+					Job:        s.Job,
+					Step:       s.Step,
+					Action:     s.Action,
+					Ref:        s.Ref,
+					Unresolved: unresolved,
+				})
+
+				repos[r.Owner+"/"+r.Repo] = true
+				workflows[r.Owner+"/"+r.Repo+"@"+r.Branch+"/"+w.Path] = true
+			}
+		}
+	}
+
+	report.Summary.Uses = len(report.Uses)
+	report.Summary.Workflows = len(workflows)
+	report.Summary.Repositories = len(repos)
+
+	return report
+}
+
+// saveUsesView outputs every use of the actions matching the `--uses` pattern
+func saveUsesView(res []ActionUsesReport) error {
+	report := findUses(res, usesPattern)
+
+	var rows [][]string
+	for _, u := range report.Uses {
+		rows = append(rows, []string{
+			u.Owner,
+			u.Repo,
+			u.Branch,
+			u.Workflow,
+			u.Job,
+			u.Step,
+			u.Action,
+			u.Ref,
+			fmt.Sprintf("%t", u.Unresolved),
+		})
+	}
+
+	if !silent {
+		fmt.Printf(
+			"Found %s uses of %s in %s workflows across %s repositories\n\n",
+			utils.Bold(report.Summary.Uses),
+			utils.Cyan(report.Summary.Pattern),
+			utils.Bold(report.Summary.Workflows),
+			utils.Bold(report.Summary.Repositories),
+		)
+
+		if report.Summary.Unresolved > 0 {
+			fmt.Printf(
+				"%s %s refs are not versions and could not be compared to the constraint\n\n",
+				utils.Orange("!"),
+				utils.Bold(report.Summary.Unresolved),
+			)
+		}
+	}
+
+	return saveActionsReport(
+		[]string{"owner", "repo", "branch", "workflow_path", "job", "step", "action", "ref", "unresolved"},
+		rows,
+		report,
+		mdActionsUsesTemplate,
+	)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_workflowUsesSteps(t *testing.T) {
+	text := `on: push
+jobs:
+  call:
+    uses: octo-org/shared/.github/workflows/ci.yml@v2
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - name: changed files
+        uses: tj-actions/changed-files@v40.1.0
+      - run: echo ok
+      - id: local
+        uses: ./.github/actions/setup
+`
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		t.Fatalf("parseWorkflow() error = %v", err)
+	}
+
+	want := []WorkflowUsesStep{
+		{Job: "call", Action: "octo-org/shared/.github/workflows/ci.yml", Ref: "v2"},
+		{Job: "build", Step: "actions/checkout@v4", Action: "actions/checkout", Ref: "v4"},
+		{Job: "build", Step: "changed files", Action: "tj-actions/changed-files", Ref: "v40.1.0"},
+		{Job: "build", Step: "local", Action: "./.github/actions/setup"},
+	}
+
+	got := workflowUsesSteps(root)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("workflowUsesSteps() = %v, want %v", got, want)
+	}
+}
+
+func Test_matchUses(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		action     string
+		ref        string
+		want       bool
+		unresolved bool
+	}{
+		{pattern: "tj-actions/changed-files", action: "tj-actions/changed-files", ref: "v40", want: true},
+		{pattern: "TJ-Actions/Changed-Files", action: "tj-actions/changed-files", ref: "v40", want: true},
+		{pattern: "tj-actions/*", action: "tj-actions/changed-files", ref: "v40", want: true},
+		{pattern: "tj-actions/*", action: "actions/checkout", ref: "v4", want: false},
+		{pattern: "octo-org/shared", action: "octo-org/shared/.github/workflows/ci.yml", ref: "v2", want: true},
+		{pattern: "tj-actions/changed-files@v40", action: "tj-actions/changed-files", ref: "v40", want: true},
+		{pattern: "tj-actions/changed-files@v4*", action: "tj-actions/changed-files", ref: "v41.0.1", want: true},
+		{pattern: "tj-actions/changed-files@<v41", action: "tj-actions/changed-files", ref: "v40.1.0", want: true},
+		{pattern: "tj-actions/changed-files@<v41", action: "tj-actions/changed-files", ref: "v41", want: false},
+		{pattern: "tj-actions/changed-files@>=41.0.1", action: "tj-actions/changed-files", ref: "v41.0.1", want: true},
+		{pattern: "tj-actions/changed-files@!=v41", action: "tj-actions/changed-files", ref: "v35", want: true},
+		{pattern: "tj-actions/changed-files@<v41", action: "tj-actions/changed-files", ref: "main", want: true, unresolved: true},
+		{pattern: "actions/checkout@>=v3", action: "actions/checkout", ref: "b4ffde65f46336ab88eb53be808477a3936bae11", want: true, unresolved: true},
+		{pattern: "actions/checkout@b4ffde*", action: "actions/checkout", ref: "b4ffde65f46336ab88eb53be808477a3936bae11", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.action+"@"+tt.ref, func(t *testing.T) {
+			got, unresolved := matchUses(tt.pattern, tt.action, tt.ref)
+			if got != tt.want || unresolved != tt.unresolved {
+				t.Errorf("matchUses() = %v, %v, want %v, %v", got, unresolved, tt.want, tt.unresolved)
+			}
+		})
+	}
+}
+
+func Test_findUses(t *testing.T) {
+	res := []ActionUsesReport{
+		{
+			Owner: "octo-org",
+			Repo:  "a",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{
+						{Job: "build", Action: "tj-actions/changed-files", Ref: "v40"},
+						{Job: "lint", Action: "tj-actions/changed-files", Ref: "v41"},
+						{Job: "build", Action: "actions/checkout", Ref: "v4"},
+					},
+				},
+			},
+		},
+		{
+			Owner: "octo-org",
+			Repo:  "b",
+			Workflows: []ActionWorkflow{
+				{
+					Path:      ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{{Job: "build", Action: "tj-actions/changed-files", Ref: "v35"}},
+				},
+				{
+					Path:      ".github/workflows/release.yml",
+					UsesSteps: []WorkflowUsesStep{{Job: "build", Action: "actions/checkout", Ref: "v4"}},
+				},
+			},
+		},
+	}
+
+	got := findUses(res, "tj-actions/changed-files")
+
+	want := ActionUsesSummary{Pattern: "tj-actions/changed-files", Uses: 3, Workflows: 2, Repositories: 2}
+	if got.Summary != want {
+		t.Errorf("findUses() summary = %v, want %v", got.Summary, want)
+	}
+}
+
+func Test_findUses_Unresolved(t *testing.T) {
+	res := []ActionUsesReport{
+		{
+			Owner: "octo-org",
+			Repo:  "a",
+			Workflows: []ActionWorkflow{
+				{
+					Path: ".github/workflows/ci.yml",
+					UsesSteps: []WorkflowUsesStep{
+						{Job: "build", Action: "actions/checkout", Ref: "v4"},
+						{Job: "lint", Action: "actions/checkout", Ref: "b4ffde65f46336ab88eb53be808477a3936bae11"},
+						{Job: "test", Action: "actions/checkout", Ref: "v2"},
+					},
+				},
+			},
+		},
+	}
+
+	got := findUses(res, "actions/checkout@>=v3")
+
+	if got.Summary.Uses != 2 || got.Summary.Unresolved != 1 {
+		t.Errorf("findUses() summary = %+v, want 2 uses with 1 unresolved", got.Summary)
+	}
+	if len(got.Uses) != 2 || got.Uses[0].Unresolved || !got.Uses[1].Unresolved {
+		t.Errorf("Expected the SHA-pinned use to be reported as unresolved, got %+v", got.Uses)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseWorkflow parses the workflow YAML text and returns its top-level mapping node.
+// The node tree keeps line and column positions, which the workflow analyzers use
+// to report where a finding is located.
+func parseWorkflow(text string) (*yaml.Node, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+		return nil, err
+	}
+
+	// empty workflow file
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+
+	root := resolveNode(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("workflow is not a YAML mapping")
+	}
+
+	return root, nil
+}
+
+// loadWorkflow returns the text and parsed workflow of a blob.
+// GraphQL truncates the text of large blobs, those are fetched in full via the REST API.
+func loadWorkflow(nameWithOwner string, blob WorkflowBlob) (string, *yaml.Node, error) {
+	if blob.IsBinary {
+		return "", nil, fmt.Errorf("binary file, not parsed")
+	}
+
+	text := blob.Text
+
+	if blob.IsTruncated {
+		var err error
+
+		if text, err = fetchBlob(nameWithOwner, blob.Oid); err != nil {
+			return "", nil, fmt.Errorf("fetching truncated file failed: %w", err)
+		}
+	}
+
+	root, err := parseWorkflow(text)
+	if err != nil {
+		return text, nil, fmt.Errorf("parsing failed: %w", err)
+	}
+
+	return text, root, nil
+}
+
+// fetchBlob returns the full content of a git blob
+func fetchBlob(nameWithOwner, oid string) (string, error) {
+	var blob struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+
+	if err := restClient.Get(fmt.Sprintf("repos/%s/git/blobs/%s", nameWithOwner, oid), &blob); err != nil {
+		return "", err
+	}
+
+	return decodeContent(blob.Content, blob.Encoding)
+}
+
+// decodeContent decodes the content of a git blob or repository file returned by the REST API
+func decodeContent(content, encoding string) (string, error) {
+	if encoding != "base64" {
+		return content, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content, "\n", ""))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// resolveNode follows YAML aliases to the node they point to
+func resolveNode(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+
+	return n
+}
+
+// mappingEntry returns the key and value node for key in mapping node n,
+// or nil if n is not a mapping or does not contain key.
+func mappingEntry(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	n = resolveNode(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], resolveNode(n.Content[i+1])
+		}
+	}
+
+	return nil, nil
+}
+
+// mappingValue returns the value node for key in mapping node n, or nil
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	_, v := mappingEntry(n, key)
+
+	return v
+}
+
+// mappingPairs calls fn for every key/value pair of mapping node n, in document order
+func mappingPairs(n *yaml.Node, fn func(k, v *yaml.Node)) {
+	n = resolveNode(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		fn(n.Content[i], resolveNode(n.Content[i+1]))
+	}
+}
+
+// workflowTriggers returns the event names of the workflow's `on:` section,
+// which can be a single event, a list of events, or a map of events with filters.
+func workflowTriggers(root *yaml.Node) []string {
+	var triggers []string
+
+	on := mappingValue(root, "on")
+	if on == nil {
+		return triggers
+	}
+
+	switch on.Kind {
+	case yaml.ScalarNode:
+		triggers = append(triggers, on.Value)
+	case yaml.SequenceNode:
+		for _, e := range on.Content {
+			if e = resolveNode(e); e.Kind == yaml.ScalarNode {
+				triggers = append(triggers, e.Value)
+			}
+		}
+	case yaml.MappingNode:
+		mappingPairs(on, func(k, _ *yaml.Node) {
+			triggers = append(triggers, k.Value)
+		})
+	}
+
+	return triggers
+}
+
+// hasTrigger reports whether the workflow is triggered by any of the given events
+func hasTrigger(root *yaml.Node, events ...string) bool {
+	for _, t := range workflowTriggers(root) {
+		for _, e := range events {
+			if t == e {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// workflowSteps calls fn for every step of every job of the workflow, in document order
+func workflowSteps(root *yaml.Node, fn func(job string, step *yaml.Node)) {
+	mappingPairs(mappingValue(root, "jobs"), func(k, v *yaml.Node) {
+		steps := mappingValue(v, "steps")
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			return
+		}
+
+		for _, s := range steps.Content {
+			if s = resolveNode(s); s.Kind == yaml.MappingNode {
+				fn(k.Value, s)
+			}
+		}
+	})
+}
+
+// stepName returns a human readable name for a step, its name, id, or the action it uses
+func stepName(step *yaml.Node) string {
+	for _, key := range []string{"name", "id", "uses"} {
+		if v := mappingValue(step, key); v != nil && v.Kind == yaml.ScalarNode && v.Value != "" {
+			return v.Value
+		}
+	}
+
+	return ""
+}
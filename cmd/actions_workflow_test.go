@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseWorkflow(t *testing.T) {
+	t.Run("empty workflow", func(t *testing.T) {
+		root, err := parseWorkflow("")
+		if err != nil {
+			t.Fatalf("parseWorkflow() error = %v", err)
+		}
+		if len(root.Content) != 0 {
+			t.Errorf("Expected empty mapping, got %d nodes", len(root.Content))
+		}
+	})
+
+	t.Run("not a mapping", func(t *testing.T) {
+		if _, err := parseWorkflow("- a\n- b\n"); err == nil {
+			t.Error("Expected error for sequence document")
+		}
+	})
+
+	t.Run("invalid YAML", func(t *testing.T) {
+		if _, err := parseWorkflow("on: [push\n"); err == nil {
+			t.Error("Expected error for invalid YAML")
+		}
+	})
+}
+
+func Test_workflowTriggers(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "single event",
+			text: "on: push\n",
+			want: []string{"push"},
+		},
+		{
+			name: "list of events",
+			text: "on: [push, pull_request]\n",
+			want: []string{"push", "pull_request"},
+		},
+		{
+			name: "map of events with filters",
+			text: "on:\n  push:\n    branches: [main]\n  workflow_dispatch:\n",
+			want: []string{"push", "workflow_dispatch"},
+		},
+		{
+			name: "no triggers",
+			text: "jobs: {}\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWorkflow(tt.text)
+			if err != nil {
+				t.Fatalf("parseWorkflow() error = %v", err)
+			}
+
+			got := workflowTriggers(root)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workflowTriggers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadWorkflow(t *testing.T) {
+	t.Run("binary file", func(t *testing.T) {
+		_, root, err := loadWorkflow("octo-org/octo-repo", WorkflowBlob{IsBinary: true})
+		if err == nil || root != nil {
+			t.Errorf("Expected error for binary file, got %v", err)
+		}
+	})
+
+	t.Run("parse error is returned", func(t *testing.T) {
+		text, _, err := loadWorkflow("octo-org/octo-repo", WorkflowBlob{Text: "on: [push\n"})
+		if err == nil {
+			t.Fatal("Expected parse error")
+		}
+		if text != "on: [push\n" {
+			t.Errorf("Expected text to be returned, got %q", text)
+		}
+	})
+
+	t.Run("complete blob", func(t *testing.T) {
+		_, root, err := loadWorkflow("octo-org/octo-repo", WorkflowBlob{Text: "on: push\n"})
+		if err != nil {
+			t.Fatalf("loadWorkflow() error = %v", err)
+		}
+		if got := workflowTriggers(root); len(got) != 1 || got[0] != "push" {
+			t.Errorf("Expected push trigger, got %v", got)
+		}
+	})
+}
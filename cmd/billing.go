@@ -29,7 +29,6 @@ import (
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/pterm/pterm"
-	"github.com/shurcooL/graphql"
 	"github.com/spf13/cobra"
 	"github.com/stoe/gh-report/internal/utils"
 )
@@ -113,11 +112,8 @@ type (
 		TotalMinutesUsed     float64 `json:"total_minutes_used"`
 		TotalPaidMinutesUsed float64 `json:"total_paid_minutes_used"`
 		IncludedMinutes      float64 `json:"included_minutes"`
-		MinutesUsedBreakdown struct {
-			MacOS   float64 `json:"MACOS"`
-			Ubuntu  float64 `json:"UBUNTU"`
-			Windows float64 `json:"WINDOWS"`
-		} `json:"minutes_used_breakdown"`
+		// Gross minutes per SKU
+		MinutesUsedBreakdown map[string]float64 `json:"minutes_used_breakdown"`
 		// Cost fields (in dollars)
 		GrossAmount    float64 `json:"gross_amount"`
 		DiscountAmount float64 `json:"discount_amount"`
@@ -215,17 +211,40 @@ func (b BillingReportJSON) MarshalJSON() ([]byte, error) {
 func init() {
 	BillingCmd.PersistentFlags().BoolVar(&all, "all", true, "Get all billing data")
 	BillingCmd.PersistentFlags().BoolVar(&actions, "actions", false, "Get GitHub Actions billing")
+	BillingCmd.PersistentFlags().StringVar(&billingBudgetPath, "budget", "", "Path to YAML file of net cost budgets per account and product (implies --forecast)")
+	BillingCmd.PersistentFlags().StringVar(&billingBy, "by", "", "Break down usage by: {repo|sku}")
 	BillingCmd.PersistentFlags().BoolVar(&packages, "packages", false, "Get GitHub Packages billing")
 	BillingCmd.PersistentFlags().BoolVar(&security, "security", false, "Get GitHub Advanced Security active committers")
 	BillingCmd.PersistentFlags().BoolVar(&storage, "storage", false, "Get shared storage billing")
 	BillingCmd.PersistentFlags().BoolVar(&showCosts, "show-costs", false, "Show cost information (net, gross, discount amounts)")
 	BillingCmd.PersistentFlags().StringVar(&billingMonth, "month", "", "Billing month for storage data (MM, defaults to current month)")
 	BillingCmd.PersistentFlags().StringVar(&billingYear, "year", "", "Billing year for storage data (YYYY, defaults to current year)")
+	BillingCmd.PersistentFlags().BoolVar(&billingForecast, "forecast", false, "Project the end-of-month usage and net cost of the current month")
+	BillingCmd.PersistentFlags().StringVar(&billingFrom, "from", "", "First billing month of a date range (YYYY-MM)")
+	BillingCmd.PersistentFlags().StringVar(&billingGranularity, "granularity", "monthly", "Granularity of the usage data: {daily|monthly}")
+	BillingCmd.PersistentFlags().BoolVar(&billingSparklines, "sparklines", false, "Render daily usage as sparklines in the terminal table (with --granularity daily)")
+	BillingCmd.PersistentFlags().IntVar(&billingTop, "top", 0, "Number of most expensive repositories to list per account, 0 for all (with --by repo)")
+	BillingCmd.PersistentFlags().Float64Var(&billingThreshold, "threshold", 0, "Minimum net cost in USD of a repository to list (with --by repo)")
+	BillingCmd.PersistentFlags().StringVar(&billingTo, "to", "", "Last billing month of a date range (YYYY-MM, defaults to current month)")
 
 	BillingCmd.MarkFlagsMutuallyExclusive("all", "actions")
 	BillingCmd.MarkFlagsMutuallyExclusive("all", "packages")
 	BillingCmd.MarkFlagsMutuallyExclusive("all", "security")
 	BillingCmd.MarkFlagsMutuallyExclusive("all", "storage")
+	BillingCmd.MarkFlagsMutuallyExclusive("from", "month")
+	BillingCmd.MarkFlagsMutuallyExclusive("from", "year")
+	BillingCmd.MarkFlagsMutuallyExclusive("to", "month")
+	BillingCmd.MarkFlagsMutuallyExclusive("to", "year")
+	BillingCmd.MarkFlagsMutuallyExclusive("forecast", "from")
+	BillingCmd.MarkFlagsMutuallyExclusive("forecast", "to")
+	BillingCmd.MarkFlagsMutuallyExclusive("forecast", "month")
+	BillingCmd.MarkFlagsMutuallyExclusive("forecast", "year")
+	BillingCmd.MarkFlagsMutuallyExclusive("forecast", "by")
+	BillingCmd.MarkFlagsMutuallyExclusive("budget", "from")
+	BillingCmd.MarkFlagsMutuallyExclusive("budget", "to")
+	BillingCmd.MarkFlagsMutuallyExclusive("budget", "month")
+	BillingCmd.MarkFlagsMutuallyExclusive("budget", "year")
+	BillingCmd.MarkFlagsMutuallyExclusive("budget", "by")
 
 	RootCmd.AddCommand(BillingCmd)
 }
@@ -249,7 +268,9 @@ func (c *PushDate) UnmarshalJSON(b []byte) error {
 
 // Helper functions to aggregate usage data from new billing API
 func aggregateActionsUsage(usageItems []UsageItem) ActionsBilling {
-	var result ActionsBilling
+	result := ActionsBilling{
+		MinutesUsedBreakdown: map[string]float64{},
+	}
 	for _, item := range usageItems {
 		if item.Product == "Actions" && item.UnitType == "minutes" {
 			// Aggregate quantities (minutes)
@@ -262,16 +283,9 @@ func aggregateActionsUsage(usageItems []UsageItem) ActionsBilling {
 			result.DiscountAmount += item.DiscountAmount
 			result.NetAmount += item.NetAmount
 
-			// Map SKU to breakdown (by gross quantity)
-			// Note: SKU string handling should be case-insensitive
-			sku := strings.ToUpper(item.SKU)
-			if strings.Contains(sku, "MACOS") || strings.Contains(sku, "MAC") {
-				result.MinutesUsedBreakdown.MacOS += item.GrossQuantity
-			} else if strings.Contains(sku, "WINDOWS") {
-				result.MinutesUsedBreakdown.Windows += item.GrossQuantity
-			} else if strings.Contains(sku, "UBUNTU") || strings.Contains(sku, "LINUX") {
-				result.MinutesUsedBreakdown.Ubuntu += item.GrossQuantity
-			}
+			// Break down by SKU (by gross quantity), so ARM, GPU, larger runners
+			// and new SKUs are kept
+			result.MinutesUsedBreakdown[item.SKU] += item.GrossQuantity
 		}
 	}
 	return result
@@ -366,7 +380,7 @@ func buildBillingEndpoint(accountType, login, path string) string {
 
 // buildBillingQueryParams constructs query parameters for billing API calls.
 // According to GitHub's documentation, month and year parameters are applicable for
-// organization and enterprise accounts. User-level accounts do not support these parameters.
+// If not provided, defaults to current month and year in UTC, the time zone of the billing periods.
 // If not provided, defaults to current month and year.
 // Month should be in MM format (e.g., "01", "12"). If month is provided without year,
 // year defaults to current year.
@@ -376,7 +390,7 @@ func buildBillingQueryParams(accountType string) string {
 		return ""
 	}
 
-	now := time.Now()
+	now := time.Now().UTC()
 
 	// Get year first (needed to build month parameter)
 	year := billingYear
@@ -404,6 +418,107 @@ func buildBillingQueryParams(accountType string) string {
 	return fmt.Sprintf("?month=%s&year=%s", month, year)
 }
 
+// billingAccounts returns the accounts to report on, the organizations of the enterprise
+// and the owner, which can be an organization or a user
+func billingAccounts() ([]BillingAccount, error) {
+	if enterprise != "" {
+		orgs, err := listEnterpriseOrganizations(enterprise)
+		if err != nil {
+			return nil, err
+		}
+
+		organizations = append(organizations, orgs...)
+	}
+
+	var accounts []BillingAccount
+
+	// Add organizations from enterprise
+	for _, org := range organizations {
+		accounts = append(accounts, BillingAccount{
+			Login:       org.Login,
+			AccountType: "organization",
+		})
+	}
+
+	// Add owner (could be org or user)
+	if owner != "" {
+		// Use the Type from the API response ("User" or "Organization")
+		// The 'user' global variable is populated by cmd.go's run() function
+		accountType := "organization"
+		if user.Type == "User" {
+			accountType = "user"
+		}
+		accounts = append(accounts, BillingAccount{
+			Login:       owner,
+			AccountType: accountType,
+		})
+	}
+
+	return accounts, nil
+}
+
+// fetchBillingUsage fetches the usage summary of an account, query are the query parameters of the request.
+// It returns false if the usage data of the account is not accessible and the account is skipped.
+func fetchBillingUsage(account BillingAccount, path, query string) (BillingUsageResponse, bool, error) {
+	var usageResponse BillingUsageResponse
+
+	endpoint := buildBillingEndpoint(account.AccountType, account.Login, path) + query
+	if err := restClient.Get(
+		endpoint,
+		&usageResponse,
+	); err != nil {
+		if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+			sp.Suffix = fmt.Sprintf(
+				" fetching %s billing report %s",
+				utils.Cyan(account.Login),
+				utils.Orange("(usage data not accessible, skipping)"),
+			)
+			return usageResponse, false, nil
+		}
+		if strings.Contains(err.Error(), "500") || strings.Contains(err.Error(), "502") || strings.Contains(err.Error(), "503") {
+			sp.Suffix = fmt.Sprintf(
+				" fetching %s billing report %s",
+				utils.Cyan(account.Login),
+				utils.Orange("(server error, skipping)"),
+			)
+			return usageResponse, false, nil
+		}
+		return usageResponse, false, err
+	}
+
+	return usageResponse, true, nil
+}
+
+// fetchBillingUsageItems fetches the usage items of path of an account for the month of the
+// month and year flags, or for every month of a date range.
+// It returns false if the usage data of the account is not accessible and the account is skipped.
+func fetchBillingUsageItems(account BillingAccount, path string, months []time.Time) ([]UsageItem, bool, error) {
+	queries := []string{buildBillingQueryParams(account.AccountType)}
+	if len(months) > 0 {
+		if account.AccountType == "user" {
+			return nil, false, fmt.Errorf("date range not supported for user accounts")
+		}
+
+		queries = nil
+		for _, m := range months {
+			queries = append(queries, billingMonthQueryParams(m))
+		}
+	}
+
+	var items []UsageItem
+
+	for _, query := range queries {
+		usageResponse, ok, err := fetchBillingUsage(account, path, query)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
+		items = append(items, usageResponse.UsageItems...)
+	}
+
+	return items, true, nil
+}
+
 // GetBilling returns GitHub billing information
 // Note: The global 'user' variable is populated by cmd.go's run() function
 // and contains the owner's Login and Type ("User" or "Organization")
@@ -423,48 +538,72 @@ func GetBilling(cmd *cobra.Command, args []string) (err error) {
 		storage = true
 	}
 
-	sp.Start()
+	if billingGranularity != "daily" && billingGranularity != "monthly" {
+		return fmt.Errorf("invalid granularity %q, must be daily or monthly", billingGranularity)
+	}
 
-	variables := map[string]interface{}{
-		"enterprise": graphql.String(enterprise),
-		"page":       (*graphql.String)(nil),
+	if billingSparklines && billingGranularity != "daily" {
+		return fmt.Errorf("--sparklines requires --granularity daily")
 	}
 
-	if enterprise != "" {
-		for {
-			graphqlClient.Query("OrgList", &enterpriseQuery, variables)
-			organizations = append(organizations, enterpriseQuery.Enterprise.Organizations.Nodes...)
+	if billingBy != "" && billingBy != "repo" && billingBy != "sku" {
+		return fmt.Errorf("invalid breakdown %q, must be repo or sku", billingBy)
+	}
 
-			if !enterpriseQuery.Enterprise.Organizations.PageInfo.HasNextPage {
-				break
-			}
+	if billingBy != "" && billingGranularity == "daily" {
+		return fmt.Errorf("--by is not supported with --granularity daily")
+	}
 
-			variables["page"] = &enterpriseQuery.Enterprise.Organizations.PageInfo.EndCursor
-		}
+	if (billingTop != 0 || billingThreshold != 0) && billingBy != "repo" {
+		return fmt.Errorf("--top and --threshold require --by repo")
 	}
 
-	var accounts []BillingAccount
+	var budgets BillingBudgets
+	if billingBudgetPath != "" {
+		if budgets, err = loadBillingBudgets(billingBudgetPath); err != nil {
+			return err
+		}
 
-	// Add organizations from enterprise
-	for _, org := range organizations {
-		accounts = append(accounts, BillingAccount{
-			Login:       org.Login,
-			AccountType: "organization",
-		})
+		billingForecast = true
 	}
 
-	// Add owner (could be org or user)
-	if owner != "" {
-		// Use the Type from the API response ("User" or "Organization")
-		// The 'user' global variable is populated by cmd.go's run() function
-		accountType := "organization"
-		if user.Type == "User" {
-			accountType = "user"
+	if billingForecast && billingGranularity == "daily" {
+		return fmt.Errorf("--forecast is not supported with --granularity daily")
+	}
+
+	var months []time.Time
+	if billingFrom != "" || billingTo != "" {
+		if months, err = billingMonths(billingFrom, billingTo, time.Now().UTC()); err != nil {
+			return err
 		}
-		accounts = append(accounts, BillingAccount{
-			Login:       owner,
-			AccountType: accountType,
-		})
+	}
+
+	sp.Start()
+
+	accounts, err := billingAccounts()
+	if err != nil {
+		sp.Stop()
+		return err
+	}
+
+	if billingForecast {
+		return getBillingForecast(accounts, budgets)
+	}
+
+	if billingBy == "repo" {
+		return getBillingByRepository(accounts, months)
+	}
+
+	if billingBy == "sku" {
+		return getBillingBySKU(accounts, months)
+	}
+
+	if billingGranularity == "daily" {
+		return getBillingDaily(accounts, months)
+	}
+
+	if len(months) > 0 {
+		return getBillingRange(accounts, months)
 	}
 
 	var billing []Billing
@@ -484,32 +623,15 @@ func GetBilling(cmd *cobra.Command, args []string) (err error) {
 				utils.HiBlack("(usage data)"),
 			)
 
-			var usageResponse BillingUsageResponse
 			// Use the summary endpoint: /settings/billing/usage/summary
 			// Query parameters are only supported for org/enterprise accounts
-			endpoint := buildBillingEndpoint(account.AccountType, account.Login, "usage/summary") + buildBillingQueryParams(account.AccountType)
-			if err := restClient.Get(
-				endpoint,
-				&usageResponse,
-			); err != nil {
-				if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
-					sp.Suffix = fmt.Sprintf(
-						" fetching %s billing report %s",
-						utils.Cyan(account.Login),
-						utils.Orange("(usage data not accessible, skipping)"),
-					)
-					continue
-				}
-				if strings.Contains(err.Error(), "500") || strings.Contains(err.Error(), "502") || strings.Contains(err.Error(), "503") {
-					sp.Suffix = fmt.Sprintf(
-						" fetching %s billing report %s",
-						utils.Cyan(account.Login),
-						utils.Orange("(server error, skipping)"),
-					)
-					continue
-				}
+			usageResponse, ok, err := fetchBillingUsage(account, "usage/summary", buildBillingQueryParams(account.AccountType))
+			if err != nil {
 				return err
 			}
+			if !ok {
+				continue
+			}
 
 			// Aggregate the usage data
 			if actions {
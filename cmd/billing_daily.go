@@ -0,0 +1,263 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	billingGranularity string
+	billingSparklines  bool
+
+	sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+	//go:embed templates/billing_daily.md.tmpl
+	mdBillingDailyTemplate string
+)
+
+type (
+	BillingDay struct {
+		Account   string  `json:"account"`
+		Product   string  `json:"product"`
+		Unit      string  `json:"unit"`
+		Date      string  `json:"date"`
+		Quantity  float64 `json:"quantity"`
+		NetAmount float64 `json:"net_amount"`
+	}
+
+	BillingTrend struct {
+		Account   string
+		Product   string
+		Unit      string
+		Quantity  []float64
+		Total     float64
+		PeakDate  string
+		PeakValue float64
+	}
+)
+
+// billingDays groups the detailed usage items of an account by day and product, ordered by date
+func billingDays(account string, products []string, usageItems []UsageItem) []BillingDay {
+	items := map[string][]UsageItem{}
+
+	for _, item := range usageItems {
+		// dates are returned as YYYY-MM-DD or as timestamp
+		date := item.Date
+		if len(date) > 10 {
+			date = date[:10]
+		}
+
+		items[date] = append(items[date], item)
+	}
+
+	var dates []string
+	for date := range items {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	var days []BillingDay
+
+	for _, product := range products {
+		for _, date := range dates {
+			quantity, netAmount, unit := billingProductUsage(product, items[date])
+
+			days = append(days, BillingDay{
+				Account:   account,
+				Product:   product,
+				Unit:      unit,
+				Date:      date,
+				Quantity:  quantity,
+				NetAmount: netAmount,
+			})
+		}
+	}
+
+	return days
+}
+
+// billingTrends summarizes the daily quantities of every account and product
+func billingTrends(days []BillingDay) []BillingTrend {
+	var trends []BillingTrend
+	index := map[string]int{}
+
+	for _, d := range days {
+		key := d.Account + "|" + d.Product
+
+		i, ok := index[key]
+		if !ok {
+			i = len(trends)
+			index[key] = i
+			trends = append(trends, BillingTrend{Account: d.Account, Product: d.Product, Unit: d.Unit})
+		}
+
+		t := &trends[i]
+		t.Quantity = append(t.Quantity, d.Quantity)
+		t.Total += d.Quantity
+
+		if t.PeakDate == "" || d.Quantity > t.PeakValue {
+			t.PeakDate = d.Date
+			t.PeakValue = d.Quantity
+		}
+	}
+
+	return trends
+}
+
+// sparkline renders values as a line of block characters scaled between their minimum and maximum
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparklineBlocks)-1))
+		}
+
+		sb.WriteRune(sparklineBlocks[i])
+	}
+
+	return sb.String()
+}
+
+// getBillingDaily reports the usage of every account and product day by day
+func getBillingDaily(accounts []BillingAccount, months []time.Time) (err error) {
+	products := billingProducts()
+	if len(products) == 0 {
+		sp.Stop()
+		return fmt.Errorf("security billing is not supported for daily granularity")
+	}
+
+	var res []BillingDay
+
+	for _, account := range accounts {
+		sp.Suffix = fmt.Sprintf(
+			" fetching %s daily billing report",
+			utils.Cyan(account.Login),
+		)
+
+		// Use the detailed endpoint: /settings/billing/usage
+		items, ok, err := fetchBillingUsageItems(account, "usage", months)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		res = append(res, billingDays(account.Login, products, items)...)
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	header := []string{"account", "product", "unit", "date", "quantity"}
+	if showCosts {
+		header = append(header, "net_amount")
+	}
+
+	var rows [][]string
+	for _, d := range res {
+		row := []string{d.Account, d.Product, d.Unit, d.Date, fmt.Sprintf("%.2f", d.Quantity)}
+		if showCosts {
+			row = append(row, fmt.Sprintf("%.2f", d.NetAmount))
+		}
+
+		rows = append(rows, row)
+	}
+
+	if !silent {
+		td := pterm.TableData{header}
+		td = append(td, rows...)
+
+		if billingSparklines {
+			td = pterm.TableData{{"account", "product", "unit", "trend", "total", "peak_date", "peak"}}
+
+			for _, t := range billingTrends(res) {
+				td = append(td, []string{
+					t.Account,
+					t.Product,
+					t.Unit,
+					utils.Cyan(sparkline(t.Quantity)),
+					fmt.Sprintf("%.2f", t.Total),
+					t.PeakDate,
+					fmt.Sprintf("%.2f", t.PeakValue),
+				})
+			}
+		}
+
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithRightAlignment(true).WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		billingReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		billingReport.SetHeader(header)
+
+		for _, row := range rows {
+			billingReport.AddData(row)
+		}
+
+		billingReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdBillingDailyTemplate, struct {
+			Data      []BillingDay
+			ShowCosts bool
+		}{
+			Data:      res,
+			ShowCosts: showCosts,
+		})
+	}
+
+	return err
+}
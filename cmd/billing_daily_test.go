@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func Test_billingDays(t *testing.T) {
+	items := []UsageItem{
+		{Date: "2025-01-02T00:00:00Z", Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", GrossQuantity: 30, NetAmount: 0.3},
+		{Date: "2025-01-01T00:00:00Z", Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", GrossQuantity: 10, NetAmount: 0.1},
+		{Date: "2025-01-01T00:00:00Z", Product: "Actions", SKU: "Actions Windows", UnitType: "minutes", GrossQuantity: 20, NetAmount: 0.4},
+		{Date: "2025-01-02", Product: "Packages", SKU: "Packages data transfer", UnitType: "gigabytes", GrossQuantity: 5, NetAmount: 0.5},
+	}
+
+	days := billingDays("octo-org", []string{"actions", "packages"}, items)
+	if len(days) != 4 {
+		t.Fatalf("Expected 4 days, got %d", len(days))
+	}
+
+	want := []BillingDay{
+		{Account: "octo-org", Product: "actions", Unit: "minutes", Date: "2025-01-01", Quantity: 30, NetAmount: 0.5},
+		{Account: "octo-org", Product: "actions", Unit: "minutes", Date: "2025-01-02", Quantity: 30, NetAmount: 0.3},
+		{Account: "octo-org", Product: "packages", Unit: "gigabytes", Date: "2025-01-01", Quantity: 0, NetAmount: 0},
+		{Account: "octo-org", Product: "packages", Unit: "gigabytes", Date: "2025-01-02", Quantity: 5, NetAmount: 0.5},
+	}
+
+	for i, d := range days {
+		if d != want[i] {
+			t.Errorf("Expected %v, got %v", want[i], d)
+		}
+	}
+}
+
+func Test_billingTrends(t *testing.T) {
+	days := []BillingDay{
+		{Account: "a", Product: "actions", Unit: "minutes", Date: "2025-01-01", Quantity: 10},
+		{Account: "a", Product: "actions", Unit: "minutes", Date: "2025-01-02", Quantity: 50},
+		{Account: "a", Product: "actions", Unit: "minutes", Date: "2025-01-03", Quantity: 20},
+		{Account: "b", Product: "actions", Unit: "minutes", Date: "2025-01-01", Quantity: 5},
+	}
+
+	trends := billingTrends(days)
+	if len(trends) != 2 {
+		t.Fatalf("Expected 2 trends, got %d", len(trends))
+	}
+
+	if trends[0].Total != 80 || trends[0].PeakDate != "2025-01-02" || trends[0].PeakValue != 50 {
+		t.Errorf("Expected total 80 peaking 2025-01-02, got %v", trends[0])
+	}
+	if len(trends[1].Quantity) != 1 {
+		t.Errorf("Expected 1 value, got %d", len(trends[1].Quantity))
+	}
+}
+
+func Test_sparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{name: "empty", values: nil, want: ""},
+		{name: "flat", values: []float64{3, 3, 3}, want: "▁▁▁"},
+		{name: "rising", values: []float64{0, 7, 14}, want: "▁▄█"},
+		{name: "spike", values: []float64{1, 1, 8, 1}, want: "▁▁█▁"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values); got != tt.want {
+				t.Errorf("sparkline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stoe/gh-report/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// forecastTrailingDays is the number of days of the trailing usage rate
+	forecastTrailingDays = 7
+
+	budgetOK     = "ok"
+	budgetAtRisk = "at-risk"
+	budgetOver   = "over"
+)
+
+var (
+	billingForecast   bool
+	billingBudgetPath string
+
+	//go:embed templates/billing_forecast.md.tmpl
+	mdBillingForecastTemplate string
+)
+
+type (
+	// BillingBudgets holds the net cost budgets in USD per account and product,
+	// the account "*" applies to every account without budgets of its own
+	BillingBudgets map[string]map[string]float64
+
+	// BillingForecast holds the projected usage of an account and product,
+	// Status is only set if a budget applies
+	BillingForecast struct {
+		Account           string  `json:"account"`
+		Product           string  `json:"product"`
+		Unit              string  `json:"unit"`
+		Quantity          float64 `json:"quantity"`
+		NetAmount         float64 `json:"net_amount"`
+		LinearQuantity    float64 `json:"linear_quantity"`
+		LinearNetAmount   float64 `json:"linear_net_amount"`
+		TrailingQuantity  float64 `json:"trailing_quantity"`
+		TrailingNetAmount float64 `json:"trailing_net_amount"`
+		Budget            float64 `json:"budget"`
+		ConsumedPercent   float64 `json:"consumed_percent"`
+		ProjectedOverrun  float64 `json:"projected_overrun"`
+		Status            string  `json:"status,omitempty"`
+	}
+)
+
+// loadBillingBudgets reads the budgets of a YAML file, e.g.
+//
+//	octo-org:
+//	  actions: 500
+//	  storage: 20
+//	"*":
+//	  actions: 100
+func loadBillingBudgets(path string) (BillingBudgets, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget file, error: %w", err)
+	}
+
+	var budgets BillingBudgets
+	if err := yaml.Unmarshal(content, &budgets); err != nil {
+		return nil, fmt.Errorf("failed to parse budget file, error: %w", err)
+	}
+
+	// reject unknown products, a typo would silently leave a product without budget
+	for _, account := range slices.Sorted(maps.Keys(budgets)) {
+		for _, product := range slices.Sorted(maps.Keys(budgets[account])) {
+			if !slices.Contains([]string{"actions", "packages", "storage"}, product) {
+				return nil, fmt.Errorf("invalid product %q for account %q in budget file, must be one of: actions, packages, storage", product, account)
+			}
+		}
+	}
+
+	return budgets, nil
+}
+
+// budget returns the budget of an account and product, falling back to the "*" account
+func (b BillingBudgets) budget(account, product string) (float64, bool) {
+	if amount, ok := b[account][product]; ok {
+		return amount, true
+	}
+
+	amount, ok := b["*"][product]
+	return amount, ok
+}
+
+// forecastBillingUsage projects the end-of-month usage of an account and product from its daily usage so far.
+// The linear projection extrapolates the average daily usage of the elapsed days, the trailing projection
+// extrapolates the average of the last seven days.
+func forecastBillingUsage(account, product, unit string, days []BillingDay, now time.Time) BillingForecast {
+	f := BillingForecast{
+		Account: account,
+		Product: product,
+		Unit:    unit,
+	}
+
+	today := now.Format("2006-01-02")
+	trailingStart := now.AddDate(0, 0, -(forecastTrailingDays - 1)).Format("2006-01-02")
+
+	var trailingQuantity, trailingNetAmount float64
+
+	for _, d := range days {
+		if d.Date > today {
+			continue
+		}
+
+		f.Quantity += d.Quantity
+		f.NetAmount += d.NetAmount
+
+		if d.Date >= trailingStart {
+			trailingQuantity += d.Quantity
+			trailingNetAmount += d.NetAmount
+		}
+	}
+
+	elapsed := float64(now.Day())
+	daysInMonth := float64(time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day())
+	remaining := daysInMonth - elapsed
+
+	// early in the month fewer than seven days have elapsed
+	trailing := min(elapsed, forecastTrailingDays)
+
+	f.LinearQuantity = f.Quantity / elapsed * daysInMonth
+	f.LinearNetAmount = f.NetAmount / elapsed * daysInMonth
+	f.TrailingQuantity = f.Quantity + trailingQuantity/trailing*remaining
+	f.TrailingNetAmount = f.NetAmount + trailingNetAmount/trailing*remaining
+
+	return f
+}
+
+// applyBillingBudget sets the percent of the budget consumed, the projected overrun and the status of a forecast.
+// The status is over when the budget is consumed, at-risk when either projection exceeds it, otherwise ok.
+func applyBillingBudget(f *BillingForecast, budget float64) {
+	f.Budget = budget
+
+	if budget > 0 {
+		f.ConsumedPercent = f.NetAmount / budget * 100
+	}
+
+	projected := max(f.LinearNetAmount, f.TrailingNetAmount)
+	f.ProjectedOverrun = max(projected-budget, 0)
+
+	switch {
+	case f.NetAmount > budget:
+		f.Status = budgetOver
+	case f.ProjectedOverrun > 0:
+		f.Status = budgetAtRisk
+	default:
+		f.Status = budgetOK
+	}
+}
+
+// billingForecasts projects the usage of every account and product of the daily usage
+func billingForecasts(days []BillingDay, budgets BillingBudgets, now time.Time) []BillingForecast {
+	var forecasts []BillingForecast
+	var keys []string
+	grouped := map[string][]BillingDay{}
+
+	for _, d := range days {
+		key := d.Account + "|" + d.Product
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		grouped[key] = append(grouped[key], d)
+	}
+
+	for _, key := range keys {
+		d := grouped[key][0]
+		f := forecastBillingUsage(d.Account, d.Product, d.Unit, grouped[key], now)
+
+		if amount, ok := budgets.budget(d.Account, d.Product); ok {
+			applyBillingBudget(&f, amount)
+		}
+
+		forecasts = append(forecasts, f)
+	}
+
+	return forecasts
+}
+
+// forecastRow returns the table row of a forecast, budget columns are empty without a budget
+func forecastRow(f BillingForecast, withBudgets bool) []string {
+	row := []string{
+		f.Account,
+		f.Product,
+		f.Unit,
+		fmt.Sprintf("%.2f", f.Quantity),
+		fmt.Sprintf("%.2f", f.NetAmount),
+		fmt.Sprintf("%.2f", f.LinearQuantity),
+		fmt.Sprintf("%.2f", f.LinearNetAmount),
+		fmt.Sprintf("%.2f", f.TrailingQuantity),
+		fmt.Sprintf("%.2f", f.TrailingNetAmount),
+	}
+
+	if !withBudgets {
+		return row
+	}
+
+	if f.Status == "" {
+		return append(row, "", "", "", "")
+	}
+
+	return append(row,
+		fmt.Sprintf("%.2f", f.Budget),
+		fmt.Sprintf("%.1f", f.ConsumedPercent),
+		fmt.Sprintf("%.2f", f.ProjectedOverrun),
+		f.Status,
+	)
+}
+
+// getBillingForecast reports the usage of the current month so far and its projected end-of-month usage
+func getBillingForecast(accounts []BillingAccount, budgets BillingBudgets) (err error) {
+	products := billingProducts()
+	if len(products) == 0 {
+		sp.Stop()
+		return fmt.Errorf("security billing is not supported for forecasts")
+	}
+
+	now := time.Now().UTC()
+
+	var days []BillingDay
+
+	for _, account := range accounts {
+		sp.Suffix = fmt.Sprintf(
+			" fetching %s billing forecast",
+			utils.Cyan(account.Login),
+		)
+
+		// Use the detailed endpoint: /settings/billing/usage
+		items, ok, err := fetchBillingUsageItems(account, "usage", nil)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		days = append(days, billingDays(account.Login, products, items)...)
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	res := billingForecasts(days, budgets, now)
+	// an empty budget file still reports the budget columns
+	withBudgets := billingBudgetPath != ""
+
+	header := []string{
+		"account",
+		"product",
+		"unit",
+		"quantity",
+		"net_amount",
+		"linear_quantity",
+		"linear_net_amount",
+		"trailing_quantity",
+		"trailing_net_amount",
+	}
+	if withBudgets {
+		header = append(header, "budget", "consumed_percent", "projected_overrun", "status")
+	}
+
+	if !silent {
+		td := pterm.TableData{header}
+
+		for _, f := range res {
+			row := forecastRow(f, withBudgets)
+
+			switch f.Status {
+			case budgetOver:
+				row[len(row)-1] = utils.Red(f.Status)
+			case budgetAtRisk:
+				row[len(row)-1] = utils.Orange(f.Status)
+			}
+
+			td = append(td, row)
+		}
+
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithRightAlignment(true).WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		billingReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		billingReport.SetHeader(header)
+
+		for _, f := range res {
+			billingReport.AddData(forecastRow(f, withBudgets))
+		}
+
+		billingReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdBillingForecastTemplate, struct {
+			Month       string
+			Data        []BillingForecast
+			WithBudgets bool
+		}{
+			Month:       now.Format("2006-01"),
+			Data:        res,
+			WithBudgets: withBudgets,
+		})
+	}
+
+	return err
+}
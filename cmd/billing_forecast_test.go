@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_loadBillingBudgets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.yml")
+	if err := os.WriteFile(path, []byte("octo-org:\n  actions: 500\n  storage: 20.5\n\"*\":\n  actions: 100\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	budgets, err := loadBillingBudgets(path)
+	if err != nil {
+		t.Fatalf("loadBillingBudgets() error = %v", err)
+	}
+
+	tests := []struct {
+		account string
+		product string
+		want    float64
+		wantOK  bool
+	}{
+		{account: "octo-org", product: "actions", want: 500, wantOK: true},
+		{account: "octo-org", product: "storage", want: 20.5, wantOK: true},
+		{account: "other-org", product: "actions", want: 100, wantOK: true},
+		{account: "other-org", product: "packages", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.account+"/"+tt.product, func(t *testing.T) {
+			got, ok := budgets.budget(tt.account, tt.product)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Expected %.2f %v, got %.2f %v", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+
+	if _, err := loadBillingBudgets(filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+		t.Errorf("Expected error for missing budget file")
+	}
+
+	// a typo in a product must not silently leave it without budget
+	typo := filepath.Join(t.TempDir(), "typo.yml")
+	if err := os.WriteFile(typo, []byte("octo-org:\n  action: 500\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadBillingBudgets(typo); err == nil || !strings.Contains(err.Error(), `"action"`) {
+		t.Errorf("Expected error for unknown product, got %v", err)
+	}
+}
+
+func Test_forecastBillingUsage(t *testing.T) {
+	// 10 of 30 days elapsed, 10 minutes per day for the first 3 days, then 20 minutes per day
+	now := time.Date(2025, time.April, 10, 12, 0, 0, 0, time.UTC)
+
+	var days []BillingDay
+	for d := 1; d <= 10; d++ {
+		quantity := 20.0
+		if d <= 3 {
+			quantity = 10
+		}
+
+		days = append(days, BillingDay{
+			Date:      time.Date(2025, time.April, d, 0, 0, 0, 0, time.UTC).Format("2006-01-02"),
+			Quantity:  quantity,
+			NetAmount: quantity / 10,
+		})
+	}
+
+	f := forecastBillingUsage("octo-org", "actions", "minutes", days, now)
+
+	if f.Quantity != 170 || f.NetAmount != 17 {
+		t.Errorf("Expected 170 minutes and 17.00 so far, got %.2f and %.2f", f.Quantity, f.NetAmount)
+	}
+	if f.LinearQuantity != 510 {
+		t.Errorf("Expected linear projection of 510, got %.2f", f.LinearQuantity)
+	}
+	// trailing 7 days average 20 per day, 20 days remaining
+	if f.TrailingQuantity != 570 {
+		t.Errorf("Expected trailing projection of 570, got %.2f", f.TrailingQuantity)
+	}
+	if math.Abs(f.TrailingNetAmount-57) > 1e-9 {
+		t.Errorf("Expected trailing net amount of 57.00, got %.2f", f.TrailingNetAmount)
+	}
+}
+
+func Test_forecastBillingUsage_EarlyMonth(t *testing.T) {
+	now := time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC)
+	days := []BillingDay{
+		{Date: "2025-02-01", Quantity: 10},
+		{Date: "2025-02-02", Quantity: 30},
+	}
+
+	f := forecastBillingUsage("octo-org", "actions", "minutes", days, now)
+
+	// fewer than 7 days elapsed, both projections use the 2 elapsed days
+	if f.LinearQuantity != 560 || f.TrailingQuantity != 560 {
+		t.Errorf("Expected projections of 560, got %.2f and %.2f", f.LinearQuantity, f.TrailingQuantity)
+	}
+}
+
+func Test_applyBillingBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast BillingForecast
+		budget   float64
+		status   string
+		consumed float64
+		overrun  float64
+	}{
+		{name: "ok", forecast: BillingForecast{NetAmount: 10, LinearNetAmount: 30, TrailingNetAmount: 40}, budget: 50, status: budgetOK, consumed: 20},
+		{name: "at risk", forecast: BillingForecast{NetAmount: 10, LinearNetAmount: 30, TrailingNetAmount: 60}, budget: 50, status: budgetAtRisk, consumed: 20, overrun: 10},
+		{name: "over", forecast: BillingForecast{NetAmount: 60, LinearNetAmount: 90, TrailingNetAmount: 80}, budget: 50, status: budgetOver, consumed: 120, overrun: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.forecast
+			applyBillingBudget(&f, tt.budget)
+
+			if f.Status != tt.status || f.ConsumedPercent != tt.consumed || f.ProjectedOverrun != tt.overrun {
+				t.Errorf("Expected %s %.1f%% %.2f, got %s %.1f%% %.2f", tt.status, tt.consumed, tt.overrun, f.Status, f.ConsumedPercent, f.ProjectedOverrun)
+			}
+		})
+	}
+}
+
+func Test_billingForecasts(t *testing.T) {
+	now := time.Date(2025, time.April, 2, 0, 0, 0, 0, time.UTC)
+	days := []BillingDay{
+		{Account: "a", Product: "actions", Unit: "minutes", Date: "2025-04-01", NetAmount: 1},
+		{Account: "a", Product: "actions", Unit: "minutes", Date: "2025-04-02", NetAmount: 1},
+		{Account: "a", Product: "storage", Unit: "gigabyte-hours", Date: "2025-04-01", NetAmount: 1},
+		{Account: "b", Product: "actions", Unit: "minutes", Date: "2025-04-01", NetAmount: 1},
+	}
+
+	forecasts := billingForecasts(days, BillingBudgets{"a": {"actions": 100}}, now)
+	if len(forecasts) != 3 {
+		t.Fatalf("Expected 3 forecasts, got %d", len(forecasts))
+	}
+
+	if forecasts[0].Status != budgetOK || forecasts[0].NetAmount != 2 {
+		t.Errorf("Expected budget status ok for a/actions, got %v", forecasts[0])
+	}
+	if forecasts[1].Status != "" || forecasts[2].Status != "" {
+		t.Errorf("Expected no budget status, got %q and %q", forecasts[1].Status, forecasts[2].Status)
+	}
+
+	if row := forecastRow(forecasts[1], true); len(row) != 13 || row[12] != "" {
+		t.Errorf("Expected empty budget columns, got %v", row)
+	}
+	if row := forecastRow(forecasts[0], false); len(row) != 9 {
+		t.Errorf("Expected 9 columns, got %d", len(row))
+	}
+}
+
+func Test_BillingForecast_JSON(t *testing.T) {
+	f := BillingForecast{Account: "octo-org", Product: "actions", Unit: "minutes"}
+	applyBillingBudget(&f, 0)
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{`"budget":0`, `"consumed_percent":0`, `"projected_overrun":0`, `"status":"ok"`} {
+		if !strings.Contains(string(b), key) {
+			t.Errorf("Expected %s in %s", key, b)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	billingFrom string
+	billingTo   string
+
+	//go:embed templates/billing_range.md.tmpl
+	mdBillingRangeTemplate string
+)
+
+type (
+	BillingRangeMonth struct {
+		Month          string  `json:"month"`
+		Quantity       float64 `json:"quantity"`
+		QuantityDelta  float64 `json:"quantity_delta"`
+		NetAmount      float64 `json:"net_amount"`
+		NetAmountDelta float64 `json:"net_amount_delta"`
+	}
+
+	BillingRangeReport struct {
+		Account        string              `json:"account"`
+		Product        string              `json:"product"`
+		Unit           string              `json:"unit"`
+		Months         []BillingRangeMonth `json:"months"`
+		TotalQuantity  float64             `json:"total_quantity"`
+		TotalNetAmount float64             `json:"total_net_amount"`
+	}
+)
+
+// billingMonths returns the first day of every month between from and to (YYYY-MM), both included.
+// to defaults to the current month.
+func billingMonths(from, to string, now time.Time) ([]time.Time, error) {
+	if from == "" {
+		return nil, fmt.Errorf("--from is required with --to")
+	}
+
+	start, err := time.Parse("2006-01", from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --from month %q, must be YYYY-MM", from)
+	}
+
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		if end, err = time.Parse("2006-01", to); err != nil {
+			return nil, fmt.Errorf("invalid --to month %q, must be YYYY-MM", to)
+		}
+	}
+
+	if start.After(end) {
+		return nil, fmt.Errorf("--from month must be before --to month")
+	}
+
+	var months []time.Time
+	for m := start; !m.After(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, m)
+	}
+
+	return months, nil
+}
+
+// billingMonthQueryParams constructs the query parameters of the usage summary for month m
+func billingMonthQueryParams(m time.Time) string {
+	return fmt.Sprintf("?month=%s&year=%s", m.Format("01"), m.Format("2006"))
+}
+
+// billingProducts returns the products of a date range report, security billing is not available per month
+func billingProducts() []string {
+	var products []string
+
+	if actions {
+		products = append(products, "actions")
+	}
+	if packages {
+		products = append(products, "packages")
+	}
+	if storage {
+		products = append(products, "storage")
+	}
+
+	return products
+}
+
+// billingProductUsage returns the quantity, net amount and unit of a product's usage items
+func billingProductUsage(product string, usageItems []UsageItem) (float64, float64, string) {
+	switch product {
+	case "actions":
+		a := aggregateActionsUsage(usageItems)
+		return a.TotalMinutesUsed, a.NetAmount, "minutes"
+	case "packages":
+		p := aggregatePackagesUsage(usageItems)
+		return p.TotalGigabytesBandwidthUsed, p.NetAmount, "gigabytes"
+	case "storage":
+		s := aggregateStorageUsage(usageItems)
+		return s.EstimatedStorageForMonth, s.NetAmount, "gigabyte-hours"
+	}
+
+	return 0, 0, ""
+}
+
+// newBillingRangeReport calculates the month-over-month deltas and range totals of months
+func newBillingRangeReport(account, product, unit string, months []BillingRangeMonth) BillingRangeReport {
+	r := BillingRangeReport{
+		Account: account,
+		Product: product,
+		Unit:    unit,
+	}
+
+	for i, m := range months {
+		if i > 0 {
+			m.QuantityDelta = m.Quantity - months[i-1].Quantity
+			m.NetAmountDelta = m.NetAmount - months[i-1].NetAmount
+		}
+
+		r.TotalQuantity += m.Quantity
+		r.TotalNetAmount += m.NetAmount
+		r.Months = append(r.Months, m)
+	}
+
+	return r
+}
+
+// billingRangeTotals sums up the months of every product over all accounts
+func billingRangeTotals(reports []BillingRangeReport, products []string) []BillingRangeReport {
+	var totals []BillingRangeReport
+
+	for _, product := range products {
+		var unit string
+		var months []BillingRangeMonth
+
+		for _, r := range reports {
+			if r.Product != product {
+				continue
+			}
+
+			unit = r.Unit
+
+			if months == nil {
+				months = make([]BillingRangeMonth, len(r.Months))
+			}
+
+			for i, m := range r.Months {
+				months[i].Month = m.Month
+				months[i].Quantity += m.Quantity
+				months[i].NetAmount += m.NetAmount
+			}
+		}
+
+		if months != nil {
+			totals = append(totals, newBillingRangeReport("total", product, unit, months))
+		}
+	}
+
+	return totals
+}
+
+// billingRangeHeader returns the columns of a date range report, a value and delta column per month
+func billingRangeHeader(months []time.Time) []string {
+	header := []string{"account", "product", "unit"}
+
+	for i, m := range months {
+		header = append(header, m.Format("2006-01"))
+		if i > 0 {
+			header = append(header, m.Format("2006-01")+"_delta")
+		}
+	}
+
+	return append(header, "total")
+}
+
+// billingRangeRows returns the quantity row of a report and, with cost information, its net amount row
+func billingRangeRows(r BillingRangeReport) [][]string {
+	quantity := []string{r.Account, r.Product, r.Unit}
+	cost := []string{r.Account, r.Product, "net_amount"}
+
+	for i, m := range r.Months {
+		quantity = append(quantity, fmt.Sprintf("%.2f", m.Quantity))
+		cost = append(cost, fmt.Sprintf("%.2f", m.NetAmount))
+
+		if i > 0 {
+			quantity = append(quantity, fmt.Sprintf("%+.2f", m.QuantityDelta))
+			cost = append(cost, fmt.Sprintf("%+.2f", m.NetAmountDelta))
+		}
+	}
+
+	quantity = append(quantity, fmt.Sprintf("%.2f", r.TotalQuantity))
+	cost = append(cost, fmt.Sprintf("%.2f", r.TotalNetAmount))
+
+	if showCosts {
+		return [][]string{quantity, cost}
+	}
+
+	return [][]string{quantity}
+}
+
+// getBillingRange reports the usage of every account and product month by month
+func getBillingRange(accounts []BillingAccount, months []time.Time) (err error) {
+	products := billingProducts()
+	if len(products) == 0 {
+		sp.Stop()
+		return fmt.Errorf("security billing is not supported for a date range")
+	}
+
+	var res []BillingRangeReport
+
+	for _, account := range accounts {
+		if account.AccountType == "user" {
+			sp.Stop()
+			return fmt.Errorf("date range not supported for user accounts")
+		}
+
+		usage := map[string][]BillingRangeMonth{}
+		units := map[string]string{}
+		skipped := false
+
+		for _, m := range months {
+			sp.Suffix = fmt.Sprintf(
+				" fetching %s billing report %s",
+				utils.Cyan(account.Login),
+				utils.HiBlack(m.Format("2006-01")),
+			)
+
+			usageResponse, ok, err := fetchBillingUsage(account, "usage/summary", billingMonthQueryParams(m))
+			if err != nil {
+				sp.Stop()
+				return err
+			}
+			if !ok {
+				skipped = true
+				break
+			}
+
+			for _, product := range products {
+				quantity, netAmount, unit := billingProductUsage(product, usageResponse.UsageItems)
+
+				units[product] = unit
+				usage[product] = append(usage[product], BillingRangeMonth{
+					Month:     m.Format("2006-01"),
+					Quantity:  quantity,
+					NetAmount: netAmount,
+				})
+			}
+		}
+
+		if skipped {
+			continue
+		}
+
+		for _, product := range products {
+			res = append(res, newBillingRangeReport(account.Login, product, units[product], usage[product]))
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	header := billingRangeHeader(months)
+	totals := billingRangeTotals(res, products)
+
+	var rows [][]string
+	for _, r := range append(res, totals...) {
+		rows = append(rows, billingRangeRows(r)...)
+	}
+
+	if !silent {
+		td := pterm.TableData{header}
+		td = append(td, rows...)
+
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithRightAlignment(true).WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		billingReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		billingReport.SetHeader(header)
+
+		for _, row := range rows {
+			billingReport.AddData(row)
+		}
+
+		billingReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, struct {
+			From     string               `json:"from"`
+			To       string               `json:"to"`
+			Accounts []BillingRangeReport `json:"accounts"`
+			Totals   []BillingRangeReport `json:"totals"`
+		}{
+			From:     months[0].Format("2006-01"),
+			To:       months[len(months)-1].Format("2006-01"),
+			Accounts: res,
+			Totals:   totals,
+		})
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdBillingRangeTemplate, struct {
+			From   string
+			To     string
+			Header []string
+			Rows   [][]string
+		}{
+			From:   months[0].Format("2006-01"),
+			To:     months[len(months)-1].Format("2006-01"),
+			Header: header,
+			Rows:   rows,
+		})
+	}
+
+	return err
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_billingMonths(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "range", from: "2024-11", to: "2025-02", want: []string{"2024-11", "2024-12", "2025-01", "2025-02"}},
+		{name: "single month", from: "2025-01", to: "2025-01", want: []string{"2025-01"}},
+		{name: "defaults to current month", from: "2025-02", want: []string{"2025-02", "2025-03"}},
+		{name: "missing from", to: "2025-02", wantErr: true},
+		{name: "invalid from", from: "2025-1", wantErr: true},
+		{name: "invalid to", from: "2025-01", to: "02/2025", wantErr: true},
+		{name: "from after to", from: "2025-03", to: "2025-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			months, err := billingMonths(tt.from, tt.to, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("billingMonths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, m := range months {
+				got = append(got, m.Format("2006-01"))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("billingMonths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_billingMonthQueryParams(t *testing.T) {
+	got := billingMonthQueryParams(time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC))
+	if got != "?month=02&year=2025" {
+		t.Errorf("Expected ?month=02&year=2025, got %s", got)
+	}
+}
+
+func Test_billingProductUsage(t *testing.T) {
+	items := []UsageItem{
+		{Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", GrossQuantity: 100, NetAmount: 0.8},
+		{Product: "Packages", SKU: "Packages data transfer", UnitType: "gigabytes", GrossQuantity: 5, NetAmount: 0.5},
+		{Product: "Actions", SKU: "Actions storage", UnitType: "gigabyte-hours", GrossQuantity: 20, NetAmount: 0.2},
+	}
+
+	tests := []struct {
+		product   string
+		quantity  float64
+		netAmount float64
+		unit      string
+	}{
+		{product: "actions", quantity: 100, netAmount: 0.8, unit: "minutes"},
+		{product: "packages", quantity: 5, netAmount: 0.5, unit: "gigabytes"},
+		{product: "storage", quantity: 20, netAmount: 0.2, unit: "gigabyte-hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.product, func(t *testing.T) {
+			quantity, netAmount, unit := billingProductUsage(tt.product, items)
+			if quantity != tt.quantity || netAmount != tt.netAmount || unit != tt.unit {
+				t.Errorf("Expected %.2f %s / %.2f, got %.2f %s / %.2f", tt.quantity, tt.unit, tt.netAmount, quantity, unit, netAmount)
+			}
+		})
+	}
+}
+
+func Test_newBillingRangeReport(t *testing.T) {
+	r := newBillingRangeReport("octo-org", "actions", "minutes", []BillingRangeMonth{
+		{Month: "2025-01", Quantity: 100, NetAmount: 1},
+		{Month: "2025-02", Quantity: 150, NetAmount: 1.5},
+		{Month: "2025-03", Quantity: 120, NetAmount: 1.2},
+	})
+
+	if r.TotalQuantity != 370 {
+		t.Errorf("Expected total quantity 370, got %.2f", r.TotalQuantity)
+	}
+	if r.Months[0].QuantityDelta != 0 {
+		t.Errorf("Expected no delta for the first month, got %.2f", r.Months[0].QuantityDelta)
+	}
+	if r.Months[1].QuantityDelta != 50 || r.Months[2].QuantityDelta != -30 {
+		t.Errorf("Expected deltas 50 and -30, got %.2f and %.2f", r.Months[1].QuantityDelta, r.Months[2].QuantityDelta)
+	}
+	if r.Months[1].NetAmountDelta != 0.5 {
+		t.Errorf("Expected net amount delta 0.50, got %.2f", r.Months[1].NetAmountDelta)
+	}
+}
+
+func Test_billingRangeTotals(t *testing.T) {
+	reports := []BillingRangeReport{
+		newBillingRangeReport("a", "actions", "minutes", []BillingRangeMonth{{Month: "2025-01", Quantity: 10}, {Month: "2025-02", Quantity: 20}}),
+		newBillingRangeReport("a", "packages", "gigabytes", []BillingRangeMonth{{Month: "2025-01", Quantity: 1}, {Month: "2025-02", Quantity: 2}}),
+		newBillingRangeReport("b", "actions", "minutes", []BillingRangeMonth{{Month: "2025-01", Quantity: 5}, {Month: "2025-02", Quantity: 5}}),
+	}
+
+	totals := billingRangeTotals(reports, []string{"actions", "packages", "storage"})
+	if len(totals) != 2 {
+		t.Fatalf("Expected 2 totals, got %d", len(totals))
+	}
+
+	if totals[0].Account != "total" || totals[0].Product != "actions" || totals[0].TotalQuantity != 40 {
+		t.Errorf("Expected actions total of 40, got %v", totals[0])
+	}
+	if totals[0].Months[1].QuantityDelta != 10 {
+		t.Errorf("Expected delta 10, got %.2f", totals[0].Months[1].QuantityDelta)
+	}
+}
+
+func Test_billingRangeHeader(t *testing.T) {
+	months := []time.Time{
+		time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	want := []string{"account", "product", "unit", "2025-01", "2025-02", "2025-02_delta", "total"}
+	if got := billingRangeHeader(months); !reflect.DeepEqual(got, want) {
+		t.Errorf("billingRangeHeader() = %v, want %v", got, want)
+	}
+}
+
+func Test_billingRangeRows(t *testing.T) {
+	r := newBillingRangeReport("octo-org", "actions", "minutes", []BillingRangeMonth{
+		{Month: "2025-01", Quantity: 100, NetAmount: 2},
+		{Month: "2025-02", Quantity: 80, NetAmount: 1},
+	})
+
+	rows := billingRangeRows(r)
+	want := [][]string{{"octo-org", "actions", "minutes", "100.00", "80.00", "-20.00", "180.00"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("billingRangeRows() = %v, want %v", rows, want)
+	}
+
+	showCosts = true
+	defer func() { showCosts = false }()
+
+	rows = billingRangeRows(r)
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[1][2] != "net_amount" || rows[1][5] != "-1.00" || rows[1][6] != "3.00" {
+		t.Errorf("Expected net amount row, got %v", rows[1])
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	billingBy        string
+	billingTop       int
+	billingThreshold float64
+
+	//go:embed templates/billing_repo.md.tmpl
+	mdBillingRepoTemplate string
+)
+
+type (
+	BillingRepository struct {
+		Account              string  `json:"account"`
+		Repository           string  `json:"repository"`
+		ActionsMinutes       float64 `json:"actions_minutes"`
+		PackagesGigabytes    float64 `json:"packages_gigabytes"`
+		StorageGigabyteHours float64 `json:"storage_gigabyte_hours"`
+		NetAmount            float64 `json:"net_amount"`
+	}
+)
+
+// billingRepositories groups the detailed usage items of an account by repository,
+// ordered by net cost, most expensive first
+func billingRepositories(account string, products []string, usageItems []UsageItem) []BillingRepository {
+	items := map[string][]UsageItem{}
+
+	for _, item := range usageItems {
+		name := item.RepositoryName
+		if name == "" {
+			// usage not attributed to a repository, e.g. Advanced Security or Copilot
+			name = "-"
+		}
+
+		items[name] = append(items[name], item)
+	}
+
+	var repos []BillingRepository
+
+	for name, ri := range items {
+		r := BillingRepository{
+			Account:    account,
+			Repository: name,
+		}
+
+		for _, product := range products {
+			quantity, _, _ := billingProductUsage(product, ri)
+
+			switch product {
+			case "actions":
+				r.ActionsMinutes = quantity
+			case "packages":
+				r.PackagesGigabytes = quantity
+			case "storage":
+				r.StorageGigabyteHours = quantity
+			}
+
+		}
+
+		// every item's cost is counted once, Packages storage is part of both
+		// the packages and the storage aggregation
+		for _, item := range ri {
+			if slices.Contains(products, billingItemProduct(item)) {
+				r.NetAmount += item.NetAmount
+			}
+		}
+
+		repos = append(repos, r)
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].NetAmount != repos[j].NetAmount {
+			return repos[i].NetAmount > repos[j].NetAmount
+		}
+		return repos[i].Repository < repos[j].Repository
+	})
+
+	return repos
+}
+
+// billingItemProduct returns the product (actions, packages or storage) a usage item is reported under,
+// or "" for usage of other products, e.g. Copilot
+func billingItemProduct(item UsageItem) string {
+	switch {
+	case item.UnitType == "gigabyte-hours":
+		return "storage"
+	case item.Product == "Actions" && item.UnitType == "minutes":
+		return "actions"
+	case item.Product == "Packages":
+		return "packages"
+	}
+
+	return ""
+}
+
+// filterBillingRepositories returns the repositories with a net cost of at least threshold,
+// limited to the top most expensive ones; top 0 returns all of them
+func filterBillingRepositories(repos []BillingRepository, top int, threshold float64) []BillingRepository {
+	var filtered []BillingRepository
+
+	for _, r := range repos {
+		if r.NetAmount < threshold {
+			continue
+		}
+
+		if top > 0 && len(filtered) == top {
+			break
+		}
+
+		filtered = append(filtered, r)
+	}
+
+	return filtered
+}
+
+// getBillingByRepository reports the usage and net cost of every repository of every account
+func getBillingByRepository(accounts []BillingAccount, months []time.Time) (err error) {
+	products := billingProducts()
+	if len(products) == 0 {
+		sp.Stop()
+		return fmt.Errorf("security billing is not supported by repository")
+	}
+
+	var res []BillingRepository
+
+	for _, account := range accounts {
+		sp.Suffix = fmt.Sprintf(
+			" fetching %s billing report by repository",
+			utils.Cyan(account.Login),
+		)
+
+		// Use the detailed endpoint: /settings/billing/usage
+		items, ok, err := fetchBillingUsageItems(account, "usage", months)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		repos := billingRepositories(account.Login, products, items)
+		res = append(res, filterBillingRepositories(repos, billingTop, billingThreshold)...)
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	header := []string{"account", "repository"}
+	if actions {
+		header = append(header, "actions_minutes")
+	}
+	if packages {
+		header = append(header, "packages_gigabytes")
+	}
+	if storage {
+		header = append(header, "storage_gigabyte_hours")
+	}
+	header = append(header, "net_amount")
+
+	var rows [][]string
+	for _, r := range res {
+		row := []string{r.Account, r.Repository}
+		if actions {
+			row = append(row, fmt.Sprintf("%.2f", r.ActionsMinutes))
+		}
+		if packages {
+			row = append(row, fmt.Sprintf("%.2f", r.PackagesGigabytes))
+		}
+		if storage {
+			row = append(row, fmt.Sprintf("%.2f", r.StorageGigabyteHours))
+		}
+		row = append(row, fmt.Sprintf("%.2f", r.NetAmount))
+
+		rows = append(rows, row)
+	}
+
+	if !silent {
+		td := pterm.TableData{header}
+		td = append(td, rows...)
+
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithRightAlignment(true).WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		billingReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		billingReport.SetHeader(header)
+
+		for _, row := range rows {
+			billingReport.AddData(row)
+		}
+
+		billingReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdBillingRepoTemplate, struct {
+			Data       []BillingRepository
+			IsActions  bool
+			IsPackages bool
+			IsStorage  bool
+		}{
+			Data:       res,
+			IsActions:  actions,
+			IsPackages: packages,
+			IsStorage:  storage,
+		})
+	}
+
+	return err
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func Test_billingRepositories(t *testing.T) {
+	items := []UsageItem{
+		{Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", GrossQuantity: 100, NetAmount: 0.8, RepositoryName: "octo-org/a"},
+		{Product: "Actions", SKU: "Actions macOS", UnitType: "minutes", GrossQuantity: 10, NetAmount: 0.8, RepositoryName: "octo-org/b"},
+		{Product: "Actions", SKU: "Actions storage", UnitType: "gigabyte-hours", GrossQuantity: 24, NetAmount: 0.1, RepositoryName: "octo-org/a"},
+		{Product: "Packages", SKU: "Packages data transfer", UnitType: "gigabytes", GrossQuantity: 5, NetAmount: 2.5, RepositoryName: "octo-org/c"},
+		{Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", GrossQuantity: 1, NetAmount: 0.01},
+	}
+
+	repos := billingRepositories("octo-org", []string{"actions", "packages", "storage"}, items)
+	if len(repos) != 4 {
+		t.Fatalf("Expected 4 repositories, got %d", len(repos))
+	}
+
+	want := []BillingRepository{
+		{Account: "octo-org", Repository: "octo-org/c", PackagesGigabytes: 5, NetAmount: 2.5},
+		{Account: "octo-org", Repository: "octo-org/a", ActionsMinutes: 100, StorageGigabyteHours: 24, NetAmount: 0.9},
+		{Account: "octo-org", Repository: "octo-org/b", ActionsMinutes: 10, NetAmount: 0.8},
+		{Account: "octo-org", Repository: "-", ActionsMinutes: 1, NetAmount: 0.01},
+	}
+
+	for i, r := range repos {
+		if r != want[i] {
+			t.Errorf("Expected %v, got %v", want[i], r)
+		}
+	}
+
+	// only requested products are included
+	repos = billingRepositories("octo-org", []string{"packages"}, items)
+	if repos[0].Repository != "octo-org/c" || repos[1].NetAmount != 0 || repos[1].ActionsMinutes != 0 {
+		t.Errorf("Expected packages usage only, got %v", repos)
+	}
+}
+
+func Test_billingRepositories_PackagesStorage(t *testing.T) {
+	items := []UsageItem{
+		{Product: "Packages", SKU: "Packages data transfer", UnitType: "gigabytes", GrossQuantity: 5, NetAmount: 0.5, RepositoryName: "octo-org/a"},
+		{Product: "Packages", SKU: "Packages storage", UnitType: "gigabyte-hours", GrossQuantity: 100, NetAmount: 0.25, RepositoryName: "octo-org/a"},
+	}
+
+	tests := []struct {
+		name      string
+		products  []string
+		netAmount float64
+	}{
+		{name: "all", products: []string{"actions", "packages", "storage"}, netAmount: 0.75},
+		{name: "packages", products: []string{"packages"}, netAmount: 0.5},
+		{name: "storage", products: []string{"storage"}, netAmount: 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := billingRepositories("octo-org", tt.products, items)
+			if len(repos) != 1 || repos[0].NetAmount != tt.netAmount {
+				t.Errorf("Expected net amount %.2f, got %v", tt.netAmount, repos)
+			}
+		})
+	}
+}
+
+func Test_billingItemProduct(t *testing.T) {
+	tests := []struct {
+		item UsageItem
+		want string
+	}{
+		{item: UsageItem{Product: "Actions", UnitType: "minutes"}, want: "actions"},
+		{item: UsageItem{Product: "Actions", UnitType: "gigabyte-hours"}, want: "storage"},
+		{item: UsageItem{Product: "Packages", UnitType: "gigabytes"}, want: "packages"},
+		{item: UsageItem{Product: "Packages", UnitType: "gigabyte-hours"}, want: "storage"},
+		{item: UsageItem{Product: "Git LFS", UnitType: "gigabyte-hours"}, want: "storage"},
+		{item: UsageItem{Product: "Copilot", UnitType: "user-months"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.item.Product+" "+tt.item.UnitType, func(t *testing.T) {
+			if got := billingItemProduct(tt.item); got != tt.want {
+				t.Errorf("billingItemProduct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_filterBillingRepositories(t *testing.T) {
+	repos := []BillingRepository{
+		{Repository: "a", NetAmount: 10},
+		{Repository: "b", NetAmount: 5},
+		{Repository: "c", NetAmount: 1},
+		{Repository: "d", NetAmount: 0},
+	}
+
+	tests := []struct {
+		name      string
+		top       int
+		threshold float64
+		want      int
+	}{
+		{name: "all", want: 4},
+		{name: "top", top: 2, want: 2},
+		{name: "threshold", threshold: 1, want: 3},
+		{name: "top and threshold", top: 2, threshold: 6, want: 1},
+		{name: "top exceeds", top: 10, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterBillingRepositories(repos, tt.top, tt.threshold); len(got) != tt.want {
+				t.Errorf("Expected %d repositories, got %d", tt.want, len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	//go:embed templates/billing_sku.md.tmpl
+	mdBillingSKUTemplate string
+)
+
+type (
+	BillingSKU struct {
+		Account          string  `json:"account"`
+		Product          string  `json:"product"`
+		SKU              string  `json:"sku"`
+		UnitType         string  `json:"unit_type"`
+		GrossQuantity    float64 `json:"gross_quantity"`
+		DiscountQuantity float64 `json:"discount_quantity"`
+		NetQuantity      float64 `json:"net_quantity"`
+		PricePerUnit     float64 `json:"price_per_unit"`
+		GrossAmount      float64 `json:"gross_amount"`
+		DiscountAmount   float64 `json:"discount_amount"`
+		NetAmount        float64 `json:"net_amount"`
+	}
+)
+
+// billingSKUs sums up the usage items of the selected products of an account per product,
+// SKU and unit type, ordered by product, SKU and unit type
+func billingSKUs(account string, usageItems []UsageItem, products []string) []BillingSKU {
+	var skus []BillingSKU
+	index := map[string]int{}
+
+	for _, item := range usageItems {
+		// only the SKUs of the selected products
+		if !slices.Contains(products, billingItemProduct(item)) {
+			continue
+		}
+
+		key := item.Product + "|" + item.SKU + "|" + item.UnitType
+
+		i, ok := index[key]
+		if !ok {
+			i = len(skus)
+			index[key] = i
+			skus = append(skus, BillingSKU{
+				Account:  account,
+				Product:  item.Product,
+				SKU:      item.SKU,
+				UnitType: item.UnitType,
+			})
+		}
+
+		s := &skus[i]
+		s.GrossQuantity += item.GrossQuantity
+		s.DiscountQuantity += item.DiscountQuantity
+		s.NetQuantity += item.NetQuantity
+		s.GrossAmount += item.GrossAmount
+		s.DiscountAmount += item.DiscountAmount
+		s.NetAmount += item.NetAmount
+
+		if item.PricePerUnit != 0 {
+			s.PricePerUnit = item.PricePerUnit
+		}
+	}
+
+	sort.SliceStable(skus, func(i, j int) bool {
+		if skus[i].Product != skus[j].Product {
+			return skus[i].Product < skus[j].Product
+		}
+		if skus[i].SKU != skus[j].SKU {
+			return skus[i].SKU < skus[j].SKU
+		}
+		return skus[i].UnitType < skus[j].UnitType
+	})
+
+	return skus
+}
+
+// getBillingBySKU reports the usage and cost of every product, SKU and unit type of every account
+func getBillingBySKU(accounts []BillingAccount, months []time.Time) (err error) {
+	var res []BillingSKU
+
+	for _, account := range accounts {
+		sp.Suffix = fmt.Sprintf(
+			" fetching %s billing report by SKU",
+			utils.Cyan(account.Login),
+		)
+
+		// Use the summary endpoint: /settings/billing/usage/summary
+		items, ok, err := fetchBillingUsageItems(account, "usage/summary", months)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		res = append(res, billingSKUs(account.Login, items, billingProducts())...)
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	header := []string{
+		"account",
+		"product",
+		"sku",
+		"unit_type",
+		"gross_quantity",
+		"discount_quantity",
+		"net_quantity",
+		"price_per_unit",
+		"gross_amount",
+		"discount_amount",
+		"net_amount",
+	}
+
+	var rows [][]string
+	for _, s := range res {
+		rows = append(rows, []string{
+			s.Account,
+			s.Product,
+			s.SKU,
+			s.UnitType,
+			fmt.Sprintf("%.2f", s.GrossQuantity),
+			fmt.Sprintf("%.2f", s.DiscountQuantity),
+			fmt.Sprintf("%.2f", s.NetQuantity),
+			fmt.Sprintf("%.4f", s.PricePerUnit),
+			fmt.Sprintf("%.2f", s.GrossAmount),
+			fmt.Sprintf("%.2f", s.DiscountAmount),
+			fmt.Sprintf("%.2f", s.NetAmount),
+		})
+	}
+
+	if !silent {
+		td := pterm.TableData{header}
+		td = append(td, rows...)
+
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithRightAlignment(true).WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		billingReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		billingReport.SetHeader(header)
+
+		for _, row := range rows {
+			billingReport.AddData(row)
+		}
+
+		billingReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdBillingSKUTemplate, res)
+	}
+
+	return err
+}
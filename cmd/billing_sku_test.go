@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_billingSKUs(t *testing.T) {
+	items := []UsageItem{
+		{Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", GrossQuantity: 100, DiscountQuantity: 20, NetQuantity: 80, PricePerUnit: 0.008, GrossAmount: 0.8, DiscountAmount: 0.16, NetAmount: 0.64},
+		{Product: "Actions", SKU: "Actions Linux ARM", UnitType: "minutes", GrossQuantity: 10, NetQuantity: 10, PricePerUnit: 0.005, GrossAmount: 0.05, NetAmount: 0.05},
+		{Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", GrossQuantity: 50, NetQuantity: 50, PricePerUnit: 0.008, GrossAmount: 0.4, NetAmount: 0.4},
+		{Product: "Actions", SKU: "Actions storage", UnitType: "gigabyte-hours", GrossQuantity: 24, NetQuantity: 24, PricePerUnit: 0.00034, GrossAmount: 0.00816, NetAmount: 0.00816},
+		{Product: "Copilot", SKU: "Copilot Business", UnitType: "user-months", GrossQuantity: 2, NetQuantity: 2, PricePerUnit: 19, GrossAmount: 38, NetAmount: 38},
+	}
+
+	skus := billingSKUs("octo-org", items, []string{"actions", "packages", "storage"})
+	if len(skus) != 3 {
+		t.Fatalf("Expected 3 SKUs, got %d", len(skus))
+	}
+
+	want := []string{"Actions Linux", "Actions Linux ARM", "Actions storage"}
+	for i, s := range skus {
+		if s.SKU != want[i] {
+			t.Errorf("Expected SKU %s at %d, got %s", want[i], i, s.SKU)
+		}
+		if s.Account != "octo-org" {
+			t.Errorf("Expected account octo-org, got %s", s.Account)
+		}
+	}
+
+	linux := skus[0]
+	if linux.GrossQuantity != 150 || linux.DiscountQuantity != 20 || linux.NetQuantity != 130 {
+		t.Errorf("Expected quantities 150/20/130, got %.2f/%.2f/%.2f", linux.GrossQuantity, linux.DiscountQuantity, linux.NetQuantity)
+	}
+	if linux.PricePerUnit != 0.008 {
+		t.Errorf("Expected price per unit 0.008, got %.4f", linux.PricePerUnit)
+	}
+	if linux.NetAmount != 1.04 {
+		t.Errorf("Expected net amount 1.04, got %.2f", linux.NetAmount)
+	}
+}
+
+func Test_billingSKUs_Products(t *testing.T) {
+	items := []UsageItem{
+		{Product: "Actions", SKU: "Actions Linux", UnitType: "minutes", NetQuantity: 80, NetAmount: 0.64},
+		{Product: "Actions", SKU: "Actions storage", UnitType: "gigabyte-hours", NetQuantity: 24, NetAmount: 0.01},
+		{Product: "Packages", SKU: "Packages data transfer", UnitType: "gigabytes", NetQuantity: 5, NetAmount: 2.5},
+	}
+
+	tests := []struct {
+		products []string
+		want     []string
+	}{
+		{products: []string{"actions"}, want: []string{"Actions Linux"}},
+		{products: []string{"storage"}, want: []string{"Actions storage"}},
+		{products: []string{"actions", "packages"}, want: []string{"Actions Linux", "Packages data transfer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.products, ","), func(t *testing.T) {
+			var got []string
+			for _, s := range billingSKUs("octo-org", items, tt.products) {
+				got = append(got, s.SKU)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("billingSKUs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
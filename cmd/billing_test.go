@@ -95,7 +95,7 @@ func Test_buildBillingQueryParams(t *testing.T) {
 		billingYear = ""
 		result := buildBillingQueryParams("organization")
 
-		now := time.Now()
+		now := time.Now().UTC()
 		expectedMonth := now.Format("01") // MM format
 		expectedYear := now.Format("2006")
 
@@ -112,7 +112,7 @@ func Test_buildBillingQueryParams(t *testing.T) {
 		billingYear = ""
 		result := buildBillingQueryParams("enterprise")
 
-		now := time.Now()
+		now := time.Now().UTC()
 		expectedMonth := now.Format("01") // MM format
 		expectedYear := now.Format("2006")
 
@@ -155,7 +155,7 @@ func Test_buildBillingQueryParams(t *testing.T) {
 		billingYear = ""
 		result := buildBillingQueryParams("organization")
 
-		now := time.Now()
+		now := time.Now().UTC()
 		expectedYear := now.Format("2006")
 
 		if !strings.Contains(result, "month=06") {
@@ -171,7 +171,7 @@ func Test_buildBillingQueryParams(t *testing.T) {
 		billingYear = "2024"
 		result := buildBillingQueryParams("organization")
 
-		now := time.Now()
+		now := time.Now().UTC()
 		expectedMonth := now.Format("01") // Current month in MM format
 
 		if !strings.Contains(result, "month="+expectedMonth) {
@@ -205,14 +205,48 @@ func Test_aggregateActionsUsage(t *testing.T) {
 	if result.NetAmount != 4.25 {
 		t.Errorf("Expected NetAmount to be 4.25, got %.2f", result.NetAmount)
 	}
-	if result.MinutesUsedBreakdown.Ubuntu != 100 {
-		t.Errorf("Expected Ubuntu minutes to be 100, got %.2f", result.MinutesUsedBreakdown.Ubuntu)
+	if result.MinutesUsedBreakdown["Actions Linux"] != 100 {
+		t.Errorf("Expected Linux minutes to be 100, got %.2f", result.MinutesUsedBreakdown["Actions Linux"])
 	}
-	if result.MinutesUsedBreakdown.MacOS != 50 {
-		t.Errorf("Expected MacOS minutes to be 50, got %.2f", result.MinutesUsedBreakdown.MacOS)
+	if result.MinutesUsedBreakdown["Actions macOS"] != 50 {
+		t.Errorf("Expected macOS minutes to be 50, got %.2f", result.MinutesUsedBreakdown["Actions macOS"])
 	}
-	if result.MinutesUsedBreakdown.Windows != 75 {
-		t.Errorf("Expected Windows minutes to be 75, got %.2f", result.MinutesUsedBreakdown.Windows)
+	if result.MinutesUsedBreakdown["Actions Windows"] != 75 {
+		t.Errorf("Expected Windows minutes to be 75, got %.2f", result.MinutesUsedBreakdown["Actions Windows"])
+	}
+	if len(result.MinutesUsedBreakdown) != 3 {
+		t.Errorf("Expected 3 SKUs in breakdown, got %d", len(result.MinutesUsedBreakdown))
+	}
+}
+
+func Test_aggregateActionsUsage_Empty(t *testing.T) {
+	result := aggregateActionsUsage([]UsageItem{
+		{Product: "Packages", UnitType: "gigabytes", SKU: "Packages data transfer", GrossQuantity: 10},
+	})
+
+	// the breakdown is serialized as an object, not null
+	if result.MinutesUsedBreakdown == nil {
+		t.Errorf("Expected empty breakdown, got nil")
+	}
+	if len(result.MinutesUsedBreakdown) != 0 {
+		t.Errorf("Expected empty breakdown, got %v", result.MinutesUsedBreakdown)
+	}
+}
+
+func Test_aggregateActionsUsage_NewSKUs(t *testing.T) {
+	usageItems := []UsageItem{
+		{Product: "Actions", UnitType: "minutes", SKU: "Actions Linux ARM", GrossQuantity: 10},
+		{Product: "Actions", UnitType: "minutes", SKU: "Actions Linux 16-core GPU", GrossQuantity: 20},
+		{Product: "Actions", UnitType: "minutes", SKU: "Actions Linux 16-core GPU", GrossQuantity: 5},
+	}
+
+	result := aggregateActionsUsage(usageItems)
+
+	if result.MinutesUsedBreakdown["Actions Linux ARM"] != 10 {
+		t.Errorf("Expected ARM minutes to be 10, got %.2f", result.MinutesUsedBreakdown["Actions Linux ARM"])
+	}
+	if result.MinutesUsedBreakdown["Actions Linux 16-core GPU"] != 25 {
+		t.Errorf("Expected GPU minutes to be 25, got %.2f", result.MinutesUsedBreakdown["Actions Linux 16-core GPU"])
 	}
 }
 
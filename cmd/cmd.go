@@ -90,6 +90,14 @@ type (
 	Organization struct {
 		Login string
 	}
+
+	RESTRepository struct {
+		Name       string `json:"name"`
+		FullName   string `json:"full_name"`
+		Visibility string `json:"visibility"`
+		Archived   bool   `json:"archived"`
+		Fork       bool   `json:"fork"`
+	}
 )
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -207,3 +215,79 @@ func ExitOnError(err error) {
 		os.Exit(1)
 	}
 }
+
+// listEnterpriseOrganizations returns the organizations of an enterprise
+func listEnterpriseOrganizations(slug string) ([]Organization, error) {
+	var orgs []Organization
+
+	variables := map[string]interface{}{
+		"enterprise": graphql.String(slug),
+		"page":       (*graphql.String)(nil),
+	}
+
+	for {
+		if err := graphqlClient.Query("OrgList", &enterpriseQuery, variables); err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, enterpriseQuery.Enterprise.Organizations.Nodes...)
+
+		if !enterpriseQuery.Enterprise.Organizations.PageInfo.HasNextPage {
+			break
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+
+		variables["page"] = &enterpriseQuery.Enterprise.Organizations.PageInfo.EndCursor
+	}
+
+	return orgs, nil
+}
+
+// listOrganizations returns the organizations of the enterprise, if any, followed by the owner account
+func listOrganizations() ([]Organization, error) {
+	var orgs []Organization
+
+	if enterprise != "" {
+		var err error
+		if orgs, err = listEnterpriseOrganizations(enterprise); err != nil {
+			return nil, err
+		}
+	}
+
+	if owner != "" {
+		orgs = append(orgs, Organization{Login: owner})
+	}
+
+	return orgs, nil
+}
+
+// listRepositories returns the repositories of an organization or user account
+func listRepositories(login string, isUser bool) ([]RESTRepository, error) {
+	var repos []RESTRepository
+
+	endpoint := "orgs/%s/repos?per_page=100&page=%d"
+	if isUser {
+		endpoint = "users/%s/repos?type=owner&per_page=100&page=%d"
+	}
+
+	for page := 1; ; page++ {
+		var r []RESTRepository
+
+		if err := restClient.Get(fmt.Sprintf(endpoint, login, page), &r); err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, r...)
+
+		if len(r) < 100 {
+			break
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	return repos, nil
+}
@@ -7,9 +7,15 @@ import (
 func Test_Root(t *testing.T) {
 	want := []string{
 		"actions",
+		"actions-settings",
+		"actions-storage",
 		"billing",
+		"environments",
 		"license",
 		"repo",
+		"runners",
+		"runs",
+		"secrets",
 		"verified-emails",
 	}
 
@@ -29,3 +35,17 @@ func Test_Root(t *testing.T) {
 		}
 	}
 }
+
+func Test_listOrganizations(t *testing.T) {
+	enterprise, owner = "", "octo-org"
+	defer func() { enterprise, owner = "", "" }()
+
+	got, err := listOrganizations()
+	if err != nil {
+		t.Fatalf("listOrganizations() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].Login != "octo-org" {
+		t.Errorf("Expected only the owner octo-org, got %v", got)
+	}
+}
@@ -0,0 +1,439 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	EnvironmentsCmd = &cobra.Command{
+		Use:   "environments",
+		Short: "Report on deployment environments and their protection rules",
+		Long: heredoc.Docf(
+			`Report on deployment environments, their protection rules and latest deployment, requires %[1]srepo%[1]s and/or %[1]sread:org%[1]s scope`,
+			"`",
+		),
+		Aliases: []string{"envs"},
+		RunE:    GetEnvironments,
+	}
+
+	unprotectedOnly = false
+
+	environmentsReport utils.CSVReport
+
+	mdEnvironmentsReport = `# GitHub Environments Report
+
+| Owner | Repo | Environment | Protected | Reviewers | Prevent Self Review | Wait Timer | Branch Policy | Custom Rules | Secrets | Latest Deployment |
+| ----- | ---- | ----------- | --------- | --------- | ------------------- | ---------: | ------------- | ------------ | ------: | ----------------- |
+{{ range . }}| {{ .Owner }} | {{ .Repo }} | {{ .Name }} | {{ if .Protected }}yes{{ else }}:warning: no{{ end }} | {{ range $i, $v := .Reviewers }}{{ if $i }}<br/>{{ end }}{{ $v }}{{ end }} | {{ .PreventSelfReview }} | {{ .WaitTimer }} | {{ .BranchPolicy }}{{ range .BranchPatterns }}<br/>` + "`" + `{{ . }}` + "`" + `{{ end }} | {{ range $i, $v := .CustomRules }}{{ if $i }}<br/>{{ end }}{{ $v }}{{ end }} | {{ .Secrets }} | {{ if .LatestDeployment }}{{ .LatestDeployment.State }} ({{ .LatestDeployment.CreatedAt.UTC.Format "2006-01-02 15:04:05 MST" }}){{ end }} |
+{{ end }}
+`
+)
+
+type (
+	EnvironmentData struct {
+		Name            string `json:"name"`
+		ProtectionRules []struct {
+			Type              string `json:"type"`
+			WaitTimer         int    `json:"wait_timer"`
+			PreventSelfReview bool   `json:"prevent_self_review"`
+			Reviewers         []struct {
+				Type     string `json:"type"`
+				Reviewer struct {
+					Login string `json:"login"`
+					Slug  string `json:"slug"`
+				} `json:"reviewer"`
+			} `json:"reviewers"`
+		} `json:"protection_rules"`
+		DeploymentBranchPolicy *struct {
+			ProtectedBranches    bool `json:"protected_branches"`
+			CustomBranchPolicies bool `json:"custom_branch_policies"`
+		} `json:"deployment_branch_policy"`
+	}
+
+	DeploymentStatus struct {
+		State     string    `json:"state"`
+		Ref       string    `json:"ref,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	Environment struct {
+		Owner             string            `json:"owner"`
+		Repo              string            `json:"repo"`
+		Name              string            `json:"name"`
+		Protected         bool              `json:"protected"`
+		Reviewers         []string          `json:"reviewers,omitempty"`
+		PreventSelfReview bool              `json:"prevent_self_review"`
+		WaitTimer         int               `json:"wait_timer"`
+		BranchPolicy      string            `json:"branch_policy"`
+		BranchPatterns    []string          `json:"branch_patterns,omitempty"`
+		CustomRules       []string          `json:"custom_rules,omitempty"`
+		Secrets           int               `json:"secrets"`
+		LatestDeployment  *DeploymentStatus `json:"latest_deployment,omitempty"`
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(EnvironmentsCmd)
+
+	EnvironmentsCmd.Flags().BoolVar(&unprotectedOnly, "unprotected", false, "Show environments without protection rules only")
+}
+
+// GetEnvironments returns the deployment environments of repositories with their protection rules
+func GetEnvironments(cmd *cobra.Command, args []string) (err error) {
+	sp.Start()
+
+	var targets []RESTRepository
+
+	if repo != "" {
+		targets = append(targets, RESTRepository{Name: repo, FullName: fmt.Sprintf("%s/%s", owner, repo)})
+	} else {
+		orgs, err := listOrganizations()
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		organizations = append(organizations, orgs...)
+
+		for _, org := range organizations {
+			sp.Suffix = fmt.Sprintf(
+				" fetching environments report %s %s",
+				utils.Cyan(org.Login),
+				utils.HiBlack("(repositories)"),
+			)
+
+			repos, err := listRepositories(org.Login, org.Login == owner && user.Type == "User")
+			if err != nil {
+				sp.Stop()
+				return err
+			}
+
+			targets = append(targets, repos...)
+		}
+	}
+
+	var res []Environment
+
+	for _, r := range targets {
+		if r.Archived {
+			continue
+		}
+
+		sp.Suffix = fmt.Sprintf(
+			" fetching environments report %s",
+			utils.Cyan(r.FullName),
+		)
+
+		environments, err := fetchEnvironments(r.FullName)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+
+		o, n, _ := strings.Cut(r.FullName, "/")
+
+		for _, e := range environments {
+			env := newEnvironment(o, n, e)
+
+			if env.Secrets, err = fetchEnvironmentDetails(r.FullName, &env); err != nil {
+				sp.Stop()
+				return err
+			}
+
+			// branch policies and custom rules count as protection once fetched
+			env.Protected = isProtected(env)
+
+			if unprotectedOnly && env.Protected {
+				continue
+			}
+
+			res = append(res, env)
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	header := []string{
+		"owner",
+		"repo",
+		"environment",
+		"protected",
+		"reviewers",
+		"prevent_self_review",
+		"wait_timer",
+		"branch_policy",
+		"custom_rules",
+		"secrets",
+		"latest_deployment",
+		"latest_deployment_at",
+	}
+
+	var td = pterm.TableData{header}
+
+	// start CSV file
+	if csvPath != "" {
+		environmentsReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		environmentsReport.SetHeader(header)
+	}
+
+	for _, e := range res {
+		var state, at string
+		if e.LatestDeployment != nil {
+			state = e.LatestDeployment.State
+			at = e.LatestDeployment.CreatedAt.UTC().Format("2006-01-02 15:04:05 MST")
+		}
+
+		policy := e.BranchPolicy
+		if len(e.BranchPatterns) > 0 {
+			policy = fmt.Sprintf("%s (%s)", policy, strings.Join(e.BranchPatterns, ", "))
+		}
+
+		data := []string{
+			e.Owner,
+			e.Repo,
+			e.Name,
+			fmt.Sprintf("%t", e.Protected),
+			strings.Join(e.Reviewers, ", "),
+			fmt.Sprintf("%t", e.PreventSelfReview),
+			fmt.Sprintf("%d", e.WaitTimer),
+			policy,
+			strings.Join(e.CustomRules, ", "),
+			fmt.Sprintf("%d", e.Secrets),
+			state,
+			at,
+		}
+
+		td = append(td, data)
+
+		if csvPath != "" {
+			environmentsReport.AddData(data)
+		}
+	}
+
+	if !silent {
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		environmentsReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdEnvironmentsReport, res)
+	}
+
+	return err
+}
+
+// fetchEnvironments returns the deployment environments of a repository
+func fetchEnvironments(nameWithOwner string) ([]EnvironmentData, error) {
+	var environments []EnvironmentData
+
+	for page := 1; ; page++ {
+		var res struct {
+			TotalCount   int               `json:"total_count"`
+			Environments []EnvironmentData `json:"environments"`
+		}
+
+		if err := restClient.Get(
+			fmt.Sprintf("repos/%s/environments?per_page=100&page=%d", nameWithOwner, page),
+			&res,
+		); err != nil {
+			// not accessible, or environments are not available for the repository
+			if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+				return environments, nil
+			}
+
+			return nil, err
+		}
+
+		environments = append(environments, res.Environments...)
+
+		if len(res.Environments) < 100 || len(environments) >= res.TotalCount {
+			break
+		}
+	}
+
+	return environments, nil
+}
+
+// fetchEnvironmentDetails adds the custom branch patterns, custom protection rules and
+// latest deployment to env, and returns the number of environment secrets
+func fetchEnvironmentDetails(nameWithOwner string, env *Environment) (int, error) {
+	base := fmt.Sprintf("repos/%s/environments/%s", nameWithOwner, url.PathEscape(env.Name))
+
+	if env.BranchPolicy == "custom" {
+		var policies struct {
+			BranchPolicies []struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+			} `json:"branch_policies"`
+		}
+
+		if err := restClient.Get(base+"/deployment-branch-policies?per_page=100", &policies); err != nil {
+			if !strings.Contains(err.Error(), "403") && !strings.Contains(err.Error(), "404") {
+				return 0, err
+			}
+		}
+
+		for _, p := range policies.BranchPolicies {
+			if p.Type == "tag" {
+				env.BranchPatterns = append(env.BranchPatterns, "tag:"+p.Name)
+			} else {
+				env.BranchPatterns = append(env.BranchPatterns, p.Name)
+			}
+		}
+	}
+
+	var rules struct {
+		CustomDeploymentProtectionRules []struct {
+			Enabled bool `json:"enabled"`
+			App     struct {
+				Slug string `json:"slug"`
+			} `json:"app"`
+		} `json:"custom_deployment_protection_rules"`
+	}
+
+	if err := restClient.Get(base+"/deployment_protection_rules", &rules); err != nil {
+		if !strings.Contains(err.Error(), "403") && !strings.Contains(err.Error(), "404") {
+			return 0, err
+		}
+	}
+
+	for _, r := range rules.CustomDeploymentProtectionRules {
+		if r.Enabled {
+			env.CustomRules = append(env.CustomRules, r.App.Slug)
+		}
+	}
+
+	var secrets struct {
+		TotalCount int `json:"total_count"`
+	}
+
+	if err := restClient.Get(base+"/secrets?per_page=1", &secrets); err != nil {
+		if !strings.Contains(err.Error(), "403") && !strings.Contains(err.Error(), "404") {
+			return 0, err
+		}
+	}
+
+	var deployments []struct {
+		ID        int64     `json:"id"`
+		Ref       string    `json:"ref"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	if err := restClient.Get(
+		fmt.Sprintf("repos/%s/deployments?environment=%s&per_page=1", nameWithOwner, url.QueryEscape(env.Name)),
+		&deployments,
+	); err != nil {
+		if !strings.Contains(err.Error(), "403") && !strings.Contains(err.Error(), "404") {
+			return 0, err
+		}
+	}
+
+	if len(deployments) > 0 {
+		var statuses []DeploymentStatus
+
+		if err := restClient.Get(
+			fmt.Sprintf("repos/%s/deployments/%d/statuses?per_page=1", nameWithOwner, deployments[0].ID),
+			&statuses,
+		); err != nil {
+			return 0, err
+		}
+
+		// deployments without a status are pending
+		status := DeploymentStatus{State: "pending", CreatedAt: deployments[0].CreatedAt}
+		if len(statuses) > 0 {
+			status = statuses[0]
+		}
+
+		status.Ref = deployments[0].Ref
+		env.LatestDeployment = &status
+	}
+
+	return secrets.TotalCount, nil
+}
+
+// newEnvironment maps the protection rules of an environment to the report
+func newEnvironment(owner, repo string, e EnvironmentData) Environment {
+	env := Environment{
+		Owner:        owner,
+		Repo:         repo,
+		Name:         e.Name,
+		BranchPolicy: "all",
+	}
+
+	for _, r := range e.ProtectionRules {
+		switch r.Type {
+		case "required_reviewers":
+			env.PreventSelfReview = r.PreventSelfReview
+
+			for _, rv := range r.Reviewers {
+				if rv.Type == "Team" {
+					env.Reviewers = append(env.Reviewers, fmt.Sprintf("%s/%s", owner, rv.Reviewer.Slug))
+				} else {
+					env.Reviewers = append(env.Reviewers, rv.Reviewer.Login)
+				}
+			}
+		case "wait_timer":
+			env.WaitTimer = r.WaitTimer
+		}
+	}
+
+	if p := e.DeploymentBranchPolicy; p != nil {
+		switch {
+		case p.ProtectedBranches:
+			env.BranchPolicy = "protected"
+		case p.CustomBranchPolicies:
+			env.BranchPolicy = "custom"
+		}
+	}
+
+	return env
+}
+
+// isProtected reports whether deployments to an environment are restricted by reviewers,
+// a wait timer, custom protection rules or a branch policy
+func isProtected(env Environment) bool {
+	return len(env.Reviewers) > 0 || env.WaitTimer > 0 || len(env.CustomRules) > 0 || env.BranchPolicy != "all"
+}
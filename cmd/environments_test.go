@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_Environments(t *testing.T) {
+	t.Skip()
+}
+
+func Test_newEnvironment(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		want      Environment
+		protected bool
+	}{
+		{
+			name: "unprotected",
+			data: `{"name": "staging", "protection_rules": [], "deployment_branch_policy": null}`,
+			want: Environment{Owner: "octo-org", Repo: "octo-repo", Name: "staging", BranchPolicy: "all"},
+		},
+		{
+			name: "reviewers, wait timer and protected branches",
+			data: `{
+				"name": "production",
+				"protection_rules": [
+					{"type": "wait_timer", "wait_timer": 30},
+					{"type": "required_reviewers", "prevent_self_review": true, "reviewers": [
+						{"type": "User", "reviewer": {"login": "monalisa"}},
+						{"type": "Team", "reviewer": {"slug": "release-managers"}}
+					]},
+					{"type": "branch_policy"}
+				],
+				"deployment_branch_policy": {"protected_branches": true, "custom_branch_policies": false}
+			}`,
+			want: Environment{
+				Owner:             "octo-org",
+				Repo:              "octo-repo",
+				Name:              "production",
+				Reviewers:         []string{"monalisa", "octo-org/release-managers"},
+				PreventSelfReview: true,
+				WaitTimer:         30,
+				BranchPolicy:      "protected",
+			},
+			protected: true,
+		},
+		{
+			name:      "custom branch policies",
+			data:      `{"name": "preview", "deployment_branch_policy": {"protected_branches": false, "custom_branch_policies": true}}`,
+			want:      Environment{Owner: "octo-org", Repo: "octo-repo", Name: "preview", BranchPolicy: "custom"},
+			protected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data EnvironmentData
+			if err := json.Unmarshal([]byte(tt.data), &data); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			got := newEnvironment("octo-org", "octo-repo", data)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+
+			if isProtected(got) != tt.protected {
+				t.Errorf("Expected protected %t, got %t", tt.protected, isProtected(got))
+			}
+		})
+	}
+}
+
+func Test_isProtected(t *testing.T) {
+	if !isProtected(Environment{BranchPolicy: "all", CustomRules: []string{"deployment-gate"}}) {
+		t.Errorf("Expected environment with a custom protection rule to be protected")
+	}
+}
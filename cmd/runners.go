@@ -0,0 +1,566 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	RunnersCmd = &cobra.Command{
+		Use:   "runners",
+		Short: "Report on GitHub Actions self-hosted runners",
+		Long: heredoc.Docf(
+			`Report on GitHub Actions self-hosted runners and runner groups, requires %[1]smanage_runners:enterprise%[1]s, %[1]sadmin:org%[1]s and/or %[1]srepo%[1]s scope.
+
+			Repository runners are only fetched for repositories the organization allows self-hosted runners for.
+
+			With %[1]s--csv%[1]s the runner groups are saved to a second file next to it, e.g. %[1]srunners_groups.csv%[1]s.`,
+			"`",
+		),
+		RunE: GetRunners,
+	}
+
+	offlineOnly = false
+
+	runnersReport utils.CSVReport
+
+	runnerGroupsHeader = []string{
+		"level",
+		"owner",
+		"name",
+		"visibility",
+		"default",
+		"inherited",
+		"public_repositories",
+		"restricted_to_workflows",
+		"selected_workflows",
+		"selected_repositories",
+		"runners",
+	}
+
+	mdRunnersReport = `# GitHub Actions Runners Report
+
+## Runners
+
+| Level | Owner | Name | OS | Status | Busy | Labels | Group |
+| ----- | ----- | ---- | -- | ------ | ---- | ------ | ----- |
+{{ range .Runners }}| {{ .Level }} | {{ .Owner }} | {{ .Name }} | {{ .OS }} | {{ if eq .Status "offline" }}:warning: {{ end }}{{ .Status }} | {{ .Busy }} | {{ range $i, $v := .Labels }}{{ if $i }}, {{ end }}` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ .Group }} |
+{{ end }}
+## Runner Groups
+
+| Level | Owner | Name | Visibility | Default | Inherited | Public Repositories | Restricted To Workflows | Selected Workflows | Selected Repositories | Runners |
+| ----- | ----- | ---- | ---------- | ------- | --------- | ------------------- | ----------------------- | ------------------ | --------------------: | ------: |
+{{ range .Groups }}| {{ .Level }} | {{ .Owner }} | {{ .Name }} | {{ if eq .Visibility "all" }}:warning: {{ end }}{{ .Visibility }} | {{ .Default }} | {{ .Inherited }} | {{ .AllowsPublicRepositories }} | {{ .RestrictedToWorkflows }} | {{ range $i, $v := .SelectedWorkflows }}{{ if $i }}<br/>{{ end }}` + "`" + `{{ $v }}` + "`" + `{{ end }} | {{ .SelectedRepositories }} | {{ .Runners }} |
+{{ end }}
+`
+)
+
+type (
+	SelfHostedRunnerData struct {
+		ID            int64         `json:"id"`
+		Name          string        `json:"name"`
+		OS            string        `json:"os"`
+		Status        string        `json:"status"`
+		Busy          bool          `json:"busy"`
+		RunnerGroupID int64         `json:"runner_group_id"`
+		Labels        []RunnerLabel `json:"labels"`
+	}
+
+	RunnerLabel struct {
+		Name string `json:"name"`
+	}
+
+	RunnerGroupData struct {
+		ID                       int64    `json:"id"`
+		Name                     string   `json:"name"`
+		Visibility               string   `json:"visibility"`
+		Default                  bool     `json:"default"`
+		Inherited                bool     `json:"inherited"`
+		AllowsPublicRepositories bool     `json:"allows_public_repositories"`
+		RestrictedToWorkflows    bool     `json:"restricted_to_workflows"`
+		SelectedWorkflows        []string `json:"selected_workflows"`
+	}
+
+	SelfHostedRunner struct {
+		Level  string   `json:"level"`
+		Owner  string   `json:"owner"`
+		Name   string   `json:"name"`
+		OS     string   `json:"os"`
+		Status string   `json:"status"`
+		Busy   bool     `json:"busy"`
+		Labels []string `json:"labels"`
+		Group  string   `json:"group,omitempty"`
+	}
+
+	RunnerGroup struct {
+		Level                    string   `json:"level"`
+		Owner                    string   `json:"owner"`
+		Name                     string   `json:"name"`
+		Visibility               string   `json:"visibility"`
+		Default                  bool     `json:"default"`
+		Inherited                bool     `json:"inherited"`
+		AllowsPublicRepositories bool     `json:"allows_public_repositories"`
+		RestrictedToWorkflows    bool     `json:"restricted_to_workflows"`
+		SelectedWorkflows        []string `json:"selected_workflows,omitempty"`
+		SelectedRepositories     int      `json:"selected_repositories"`
+		Runners                  int      `json:"runners"`
+	}
+
+	RunnersReportJSON struct {
+		Runners []SelfHostedRunner `json:"runners"`
+		Groups  []RunnerGroup      `json:"groups"`
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(RunnersCmd)
+
+	RunnersCmd.Flags().BoolVar(&offlineOnly, "offline", false, "Show offline runners only")
+}
+
+// GetRunners returns the self-hosted runners and runner groups of an enterprise, organization or repository
+func GetRunners(cmd *cobra.Command, args []string) (err error) {
+	sp.Start()
+
+	res := RunnersReportJSON{
+		Runners: []SelfHostedRunner{},
+		Groups:  []RunnerGroup{},
+	}
+
+	add := func(level, login, runnersPath, groupsPath string) error {
+		sp.Suffix = fmt.Sprintf(
+			" fetching runners report %s",
+			utils.Cyan(login),
+		)
+
+		runners, groups, err := fetchRunners(level, login, runnersPath, groupsPath)
+		if err != nil {
+			return err
+		}
+
+		res.Runners = append(res.Runners, runners...)
+		res.Groups = append(res.Groups, groups...)
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+
+		return nil
+	}
+
+	if repo != "" {
+		nwo := fmt.Sprintf("%s/%s", owner, repo)
+
+		if err := add("repository", nwo, fmt.Sprintf("repos/%s/actions/runners", nwo), ""); err != nil {
+			sp.Stop()
+			return err
+		}
+	} else {
+		if enterprise != "" {
+			if err := add(
+				"enterprise",
+				enterprise,
+				fmt.Sprintf("enterprises/%s/actions/runners", enterprise),
+				fmt.Sprintf("enterprises/%s/actions/runner-groups", enterprise),
+			); err != nil {
+				sp.Stop()
+				return err
+			}
+		}
+
+		orgs, err := listOrganizations()
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		organizations = append(organizations, orgs...)
+
+		for _, org := range organizations {
+			isUser := org.Login == owner && user.Type == "User"
+
+			// user accounts have no organization runners
+			if !isUser {
+				if err := add(
+					"organization",
+					org.Login,
+					fmt.Sprintf("orgs/%s/actions/runners", org.Login),
+					fmt.Sprintf("orgs/%s/actions/runner-groups", org.Login),
+				); err != nil {
+					sp.Stop()
+					return err
+				}
+			}
+
+			repos, err := listRepositories(org.Login, isUser)
+			if err != nil {
+				sp.Stop()
+				return err
+			}
+
+			// only the repositories the organization allows repository-level self-hosted runners for
+			policy, selected := "all", map[string]bool{}
+			if !isUser {
+				if policy, selected, err = fetchSelfHostedRunnerPolicy(org.Login); err != nil {
+					sp.Stop()
+					return err
+				}
+			}
+
+			for _, r := range runnerRepositories(repos, policy, selected) {
+				if err := add("repository", r.FullName, fmt.Sprintf("repos/%s/actions/runners", r.FullName), ""); err != nil {
+					sp.Stop()
+					return err
+				}
+			}
+		}
+	}
+
+	sp.Stop()
+
+	if offlineOnly {
+		res.Runners = filterOfflineRunners(res.Runners)
+	}
+
+	header := []string{"level", "owner", "name", "os", "status", "busy", "labels", "group"}
+
+	var td = pterm.TableData{header}
+
+	// start CSV file
+	if csvPath != "" {
+		runnersReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		runnersReport.SetHeader(header)
+	}
+
+	for _, r := range res.Runners {
+		data := []string{
+			r.Level,
+			r.Owner,
+			r.Name,
+			r.OS,
+			r.Status,
+			fmt.Sprintf("%t", r.Busy),
+			strings.Join(r.Labels, ", "),
+			r.Group,
+		}
+
+		td = append(td, data)
+
+		if csvPath != "" {
+			runnersReport.AddData(data)
+		}
+	}
+
+	if !silent {
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(td).Render()
+		fmt.Println("")
+
+		gtd := pterm.TableData{runnerGroupsHeader}
+
+		for _, g := range res.Groups {
+			gtd = append(gtd, runnerGroupRow(g))
+		}
+
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(gtd).Render()
+	}
+
+	if csvPath != "" {
+		runnersReport.Save()
+
+		// runner groups are saved next to the runners CSV file
+		groupsReport, err := utils.NewCSVReport(runnerGroupsCSVPath(csvPath))
+		if err != nil {
+			return err
+		}
+
+		groupsReport.SetHeader(runnerGroupsHeader)
+
+		for _, g := range res.Groups {
+			groupsReport.AddData(runnerGroupRow(g))
+		}
+
+		groupsReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdRunnersReport, res)
+	}
+
+	return err
+}
+
+// fetchSelfHostedRunnerPolicy returns which repositories of an organization can use repository-level
+// self-hosted runners, one of all, selected or none, and the selected repositories by full name
+func fetchSelfHostedRunnerPolicy(org string) (string, map[string]bool, error) {
+	selected := map[string]bool{}
+
+	var settings struct {
+		EnabledRepositories string `json:"enabled_repositories"`
+	}
+
+	if err := restClient.Get(fmt.Sprintf("orgs/%s/actions/permissions/self-hosted-runners", org), &settings); err != nil {
+		// not accessible or not supported, assume all repositories can have runners
+		if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+			return "all", selected, nil
+		}
+
+		return "", nil, err
+	}
+
+	if settings.EnabledRepositories != "selected" {
+		return settings.EnabledRepositories, selected, nil
+	}
+
+	for page := 1; ; page++ {
+		var res struct {
+			TotalCount   int              `json:"total_count"`
+			Repositories []RESTRepository `json:"repositories"`
+		}
+
+		if err := restClient.Get(
+			fmt.Sprintf("orgs/%s/actions/permissions/self-hosted-runners/repositories?per_page=100&page=%d", org, page),
+			&res,
+		); err != nil {
+			return "", nil, err
+		}
+
+		for _, r := range res.Repositories {
+			selected[strings.ToLower(r.FullName)] = true
+		}
+
+		if len(res.Repositories) < 100 || len(selected) >= res.TotalCount {
+			break
+		}
+	}
+
+	return "selected", selected, nil
+}
+
+// runnerRepositories returns the repositories that can have repository-level self-hosted runners,
+// skipping archived repositories and the ones the organization's policy does not allow
+func runnerRepositories(repos []RESTRepository, policy string, selected map[string]bool) []RESTRepository {
+	var res []RESTRepository
+
+	for _, r := range repos {
+		if r.Archived || policy == "none" {
+			continue
+		}
+
+		if policy == "selected" && !selected[strings.ToLower(r.FullName)] {
+			continue
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
+
+// fetchRunners returns the self-hosted runners and runner groups of an enterprise, organization or repository.
+// Repositories have no runner groups, groupsPath is empty for them.
+func fetchRunners(level, login, runnersPath, groupsPath string) ([]SelfHostedRunner, []RunnerGroup, error) {
+	var data []SelfHostedRunnerData
+
+	for page := 1; ; page++ {
+		var res struct {
+			TotalCount int                    `json:"total_count"`
+			Runners    []SelfHostedRunnerData `json:"runners"`
+		}
+
+		if err := restClient.Get(fmt.Sprintf("%s?per_page=100&page=%d", runnersPath, page), &res); err != nil {
+			// not accessible or Actions disabled
+			if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+				return nil, nil, nil
+			}
+
+			return nil, nil, err
+		}
+
+		data = append(data, res.Runners...)
+
+		if len(res.Runners) < 100 || len(data) >= res.TotalCount {
+			break
+		}
+	}
+
+	var groupData []RunnerGroupData
+
+	if groupsPath != "" {
+		for page := 1; ; page++ {
+			var res struct {
+				TotalCount   int               `json:"total_count"`
+				RunnerGroups []RunnerGroupData `json:"runner_groups"`
+			}
+
+			if err := restClient.Get(fmt.Sprintf("%s?per_page=100&page=%d", groupsPath, page), &res); err != nil {
+				if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+					break
+				}
+
+				return nil, nil, err
+			}
+
+			groupData = append(groupData, res.RunnerGroups...)
+
+			if len(res.RunnerGroups) < 100 || len(groupData) >= res.TotalCount {
+				break
+			}
+		}
+	}
+
+	var selected = map[int64]int{}
+
+	for _, g := range groupData {
+		if g.Visibility != "selected" {
+			continue
+		}
+
+		// enterprise runner groups are shared with organizations, organization runner groups with repositories
+		path := "repositories"
+		if level == "enterprise" {
+			path = "organizations"
+		}
+
+		var res struct {
+			TotalCount int `json:"total_count"`
+		}
+
+		if err := restClient.Get(fmt.Sprintf("%s/%d/%s?per_page=1", groupsPath, g.ID, path), &res); err != nil {
+			if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+				continue
+			}
+
+			return nil, nil, err
+		}
+
+		selected[g.ID] = res.TotalCount
+	}
+
+	runners, groups := runnerInventory(level, login, data, groupData, selected)
+
+	return runners, groups, nil
+}
+
+// runnerInventory maps the runners and runner groups of an account to the report,
+// resolving the group name of every runner and counting the runners per group
+func runnerInventory(level, login string, data []SelfHostedRunnerData, groupData []RunnerGroupData, selected map[int64]int) ([]SelfHostedRunner, []RunnerGroup) {
+	names := map[int64]string{}
+	counts := map[int64]int{}
+
+	for _, g := range groupData {
+		names[g.ID] = g.Name
+	}
+
+	var runners []SelfHostedRunner
+	for _, r := range data {
+		var labels []string
+		for _, l := range r.Labels {
+			labels = append(labels, l.Name)
+		}
+
+		counts[r.RunnerGroupID]++
+
+		runners = append(runners, SelfHostedRunner{
+			Level:  level,
+			Owner:  login,
+			Name:   r.Name,
+			OS:     r.OS,
+			Status: r.Status,
+			Busy:   r.Busy,
+			Labels: labels,
+			Group:  names[r.RunnerGroupID],
+		})
+	}
+
+	var groups []RunnerGroup
+	for _, g := range groupData {
+		groups = append(groups, RunnerGroup{
+			Level:                    level,
+			Owner:                    login,
+			Name:                     g.Name,
+			Visibility:               g.Visibility,
+			Default:                  g.Default,
+			Inherited:                g.Inherited,
+			AllowsPublicRepositories: g.AllowsPublicRepositories,
+			RestrictedToWorkflows:    g.RestrictedToWorkflows,
+			SelectedWorkflows:        g.SelectedWorkflows,
+			SelectedRepositories:     selected[g.ID],
+			Runners:                  counts[g.ID],
+		})
+	}
+
+	return runners, groups
+}
+
+// filterOfflineRunners returns the runners that are offline
+func filterOfflineRunners(runners []SelfHostedRunner) []SelfHostedRunner {
+	var offline = []SelfHostedRunner{}
+
+	for _, r := range runners {
+		if r.Status == "offline" {
+			offline = append(offline, r)
+		}
+	}
+
+	return offline
+}
+
+// runnerGroupRow returns the table and CSV row of a runner group
+func runnerGroupRow(g RunnerGroup) []string {
+	return []string{
+		g.Level,
+		g.Owner,
+		g.Name,
+		g.Visibility,
+		fmt.Sprintf("%t", g.Default),
+		fmt.Sprintf("%t", g.Inherited),
+		fmt.Sprintf("%t", g.AllowsPublicRepositories),
+		fmt.Sprintf("%t", g.RestrictedToWorkflows),
+		strings.Join(g.SelectedWorkflows, ", "),
+		fmt.Sprintf("%d", g.SelectedRepositories),
+		fmt.Sprintf("%d", g.Runners),
+	}
+}
+
+// runnerGroupsCSVPath returns the path of the runner groups CSV file next to the runners CSV file p,
+// e.g. runners.csv becomes runners_groups.csv
+func runnerGroupsCSVPath(p string) string {
+	ext := filepath.Ext(p)
+
+	return strings.TrimSuffix(p, ext) + "_groups" + ext
+}
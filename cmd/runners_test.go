@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Runners(t *testing.T) {
+	t.Skip()
+}
+
+func Test_runnerInventory(t *testing.T) {
+	data := []SelfHostedRunnerData{
+		{ID: 1, Name: "runner-1", OS: "linux", Status: "online", Busy: true, RunnerGroupID: 1, Labels: []RunnerLabel{{Name: "self-hosted"}, {Name: "linux"}}},
+		{ID: 2, Name: "runner-2", OS: "windows", Status: "offline", RunnerGroupID: 2},
+	}
+
+	groups := []RunnerGroupData{
+		{ID: 1, Name: "Default", Visibility: "all", Default: true},
+		{ID: 2, Name: "restricted", Visibility: "selected", RestrictedToWorkflows: true, SelectedWorkflows: []string{"octo-org/ci/.github/workflows/deploy.yml@main"}},
+	}
+
+	runners, rgs := runnerInventory("organization", "octo-org", data, groups, map[int64]int{2: 3})
+
+	wantRunners := []SelfHostedRunner{
+		{Level: "organization", Owner: "octo-org", Name: "runner-1", OS: "linux", Status: "online", Busy: true, Labels: []string{"self-hosted", "linux"}, Group: "Default"},
+		{Level: "organization", Owner: "octo-org", Name: "runner-2", OS: "windows", Status: "offline", Group: "restricted"},
+	}
+
+	if !reflect.DeepEqual(runners, wantRunners) {
+		t.Errorf("Expected %+v, got %+v", wantRunners, runners)
+	}
+
+	wantGroups := []RunnerGroup{
+		{Level: "organization", Owner: "octo-org", Name: "Default", Visibility: "all", Default: true, Runners: 1},
+		{
+			Level:                 "organization",
+			Owner:                 "octo-org",
+			Name:                  "restricted",
+			Visibility:            "selected",
+			RestrictedToWorkflows: true,
+			SelectedWorkflows:     []string{"octo-org/ci/.github/workflows/deploy.yml@main"},
+			SelectedRepositories:  3,
+			Runners:               1,
+		},
+	}
+
+	if !reflect.DeepEqual(rgs, wantGroups) {
+		t.Errorf("Expected %+v, got %+v", wantGroups, rgs)
+	}
+}
+
+func Test_filterOfflineRunners(t *testing.T) {
+	got := filterOfflineRunners([]SelfHostedRunner{
+		{Name: "runner-1", Status: "online"},
+		{Name: "runner-2", Status: "offline"},
+	})
+
+	if len(got) != 1 || got[0].Name != "runner-2" {
+		t.Errorf("Expected runner-2 only, got %+v", got)
+	}
+}
+
+func Test_runnerGroupsCSVPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "runners.csv", want: "runners_groups.csv"},
+		{path: "out/report.csv", want: "out/report_groups.csv"},
+		{path: "runners", want: "runners_groups"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := runnerGroupsCSVPath(tt.path); got != tt.want {
+				t.Errorf("runnerGroupsCSVPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runnerGroupRow(t *testing.T) {
+	got := runnerGroupRow(RunnerGroup{
+		Level:             "organization",
+		Owner:             "octo-org",
+		Name:              "Default",
+		Visibility:        "all",
+		Default:           true,
+		Inherited:         true,
+		SelectedWorkflows: []string{"octo-org/a/.github/workflows/ci.yml@main", "octo-org/b/.github/workflows/ci.yml@main"},
+		Runners:           2,
+	})
+	if len(got) != len(runnerGroupsHeader) {
+		t.Fatalf("Expected %d columns, got %d", len(runnerGroupsHeader), len(got))
+	}
+	if got[4] != "true" || got[5] != "true" || got[10] != "2" {
+		t.Errorf("Expected default and inherited true and 2 runners, got %v", got)
+	}
+	if want := "octo-org/a/.github/workflows/ci.yml@main, octo-org/b/.github/workflows/ci.yml@main"; got[8] != want {
+		t.Errorf("Expected selected workflows %q, got %q", want, got[8])
+	}
+}
+
+func Test_runnerRepositories(t *testing.T) {
+	repos := []RESTRepository{
+		{Name: "a", FullName: "octo-org/a"},
+		{Name: "b", FullName: "octo-org/B"},
+		{Name: "c", FullName: "octo-org/c", Archived: true},
+	}
+
+	tests := []struct {
+		policy   string
+		selected map[string]bool
+		want     []string
+	}{
+		{policy: "all", want: []string{"octo-org/a", "octo-org/B"}},
+		{policy: "selected", selected: map[string]bool{"octo-org/b": true, "octo-org/c": true}, want: []string{"octo-org/B"}},
+		{policy: "none", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			var got []string
+			for _, r := range runnerRepositories(repos, tt.policy, tt.selected) {
+				got = append(got, r.FullName)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runnerRepositories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
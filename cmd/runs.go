@@ -0,0 +1,536 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	RunsCmd = &cobra.Command{
+		Use:   "runs",
+		Short: "Report on GitHub Actions workflow runs",
+		Long: heredoc.Docf(
+			`Report on GitHub Actions workflow runs and job timings, requires %[1]srepo%[1]s scope.
+
+			Billable minutes are estimated from the wall time of the jobs on GitHub-hosted runners,
+			rounded up per job; public repositories are not billed and report 0 billable minutes.
+			Minutes of larger runners whose labels do not name the operating system are reported as %[1]sunknown%[1]s.
+			The GitHub API returns at most 1000 runs per repository for a period, use a shorter
+			period with %[1]s--from%[1]s and %[1]s--to%[1]s for busy repositories.`,
+			"`",
+		),
+		RunE: GetRuns,
+	}
+
+	runsFrom string
+	runsTo   string
+	runsTop  int
+
+	runsReport utils.CSVReport
+
+	mdRunsReport = `# GitHub Actions Runs Report
+
+**Period**: {{ .From }} - {{ .To }}
+
+| Owner | Repo | Workflow | Runs | Success | Failure | Cancelled | Median Duration | P95 Duration | Billable Minutes |
+| ----- | ---- | -------- | ---: | ------: | ------: | --------: | --------------: | -----------: | ---------------- |
+{{ range .Workflows }}| {{ .Owner }} | {{ .Repo }} | {{ .Workflow }} | {{ .Runs }} | {{ printf "%.1f" .SuccessRate }}% | {{ printf "%.1f" .FailureRate }}% | {{ printf "%.1f" .CancelRate }}% | {{ .MedianDuration }}s | {{ .P95Duration }}s | {{ range $os, $m := .BillableMinutes }}{{ $os }}: {{ $m }}<br/>{{ end }} |
+{{ end }}
+## Top {{ len .Top }} Most Expensive Workflows
+
+| Owner | Repo | Workflow | Billable Minutes |
+| ----- | ---- | -------- | ---------------: |
+{{ range .Top }}| {{ .Owner }} | {{ .Repo }} | {{ .Workflow }} | {{ .TotalBillableMinutes }} |
+{{ end }}
+`
+)
+
+type (
+	WorkflowRunData struct {
+		ID           int64     `json:"id"`
+		Name         string    `json:"name"`
+		Path         string    `json:"path"`
+		Status       string    `json:"status"`
+		Conclusion   string    `json:"conclusion"`
+		RunStartedAt time.Time `json:"run_started_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	}
+
+	WorkflowJobData struct {
+		Name        string    `json:"name"`
+		Status      string    `json:"status"`
+		Conclusion  string    `json:"conclusion"`
+		StartedAt   time.Time `json:"started_at"`
+		CompletedAt time.Time `json:"completed_at"`
+		Labels      []string  `json:"labels"`
+	}
+
+	WorkflowRunStats struct {
+		Owner                string         `json:"owner"`
+		Repo                 string         `json:"repo"`
+		Workflow             string         `json:"workflow"`
+		Path                 string         `json:"path"`
+		Runs                 int            `json:"runs"`
+		Success              int            `json:"success"`
+		Failure              int            `json:"failure"`
+		Cancelled            int            `json:"cancelled"`
+		SuccessRate          float64        `json:"success_rate"`
+		FailureRate          float64        `json:"failure_rate"`
+		CancelRate           float64        `json:"cancel_rate"`
+		MedianDuration       int            `json:"median_duration_seconds"`
+		P95Duration          int            `json:"p95_duration_seconds"`
+		BillableMinutes      map[string]int `json:"billable_minutes"`
+		TotalBillableMinutes int            `json:"total_billable_minutes"`
+	}
+
+	RunsReportJSON struct {
+		From      string             `json:"from"`
+		To        string             `json:"to"`
+		Workflows []WorkflowRunStats `json:"workflows"`
+		Top       []WorkflowRunStats `json:"top"`
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(RunsCmd)
+
+	RunsCmd.Flags().StringVar(&runsFrom, "from", "", "Start date of the report period, YYYY-MM-DD (default: 30 days ago)")
+	RunsCmd.Flags().StringVar(&runsTo, "to", "", "End date of the report period, YYYY-MM-DD (default: today)")
+	RunsCmd.Flags().IntVar(&runsTop, "top", 10, "Number of most expensive workflows to list")
+}
+
+// GetRuns returns statistics of the GitHub Actions workflow runs in a date window
+func GetRuns(cmd *cobra.Command, args []string) (err error) {
+	from, to, err := runsPeriod(runsFrom, runsTo, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	sp.Start()
+
+	var targets []RESTRepository
+
+	if repo != "" {
+		var r RESTRepository
+		if err := restClient.Get(fmt.Sprintf("repos/%s/%s", owner, repo), &r); err != nil {
+			sp.Stop()
+			return err
+		}
+
+		targets = append(targets, r)
+	} else {
+		orgs, err := listOrganizations()
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		organizations = append(organizations, orgs...)
+
+		for _, org := range organizations {
+			sp.Suffix = fmt.Sprintf(
+				" fetching runs report %s %s",
+				utils.Cyan(org.Login),
+				utils.HiBlack("(repositories)"),
+			)
+
+			repos, err := listRepositories(org.Login, org.Login == owner && user.Type == "User")
+			if err != nil {
+				sp.Stop()
+				return err
+			}
+
+			targets = append(targets, repos...)
+		}
+	}
+
+	var stats []WorkflowRunStats
+
+	for _, r := range targets {
+		if r.Archived {
+			continue
+		}
+
+		runs, jobs, err := fetchWorkflowRuns(r.FullName, from, to)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+
+		o, n, _ := strings.Cut(r.FullName, "/")
+		stats = append(stats, workflowRunStats(o, n, r.Visibility != "public", runs, jobs)...)
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	res := RunsReportJSON{
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Workflows: stats,
+		Top:       topWorkflowRuns(stats, runsTop),
+	}
+
+	header := []string{
+		"owner",
+		"repo",
+		"workflow",
+		"runs",
+		"success_rate",
+		"failure_rate",
+		"cancel_rate",
+		"median_duration",
+		"p95_duration",
+		"billable_minutes",
+		"total_billable_minutes",
+	}
+
+	var td = pterm.TableData{header}
+
+	// start CSV file
+	if csvPath != "" {
+		runsReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		runsReport.SetHeader(header)
+	}
+
+	for _, s := range res.Workflows {
+		data := []string{
+			s.Owner,
+			s.Repo,
+			s.Workflow,
+			fmt.Sprintf("%d", s.Runs),
+			fmt.Sprintf("%.1f%%", s.SuccessRate),
+			fmt.Sprintf("%.1f%%", s.FailureRate),
+			fmt.Sprintf("%.1f%%", s.CancelRate),
+			(time.Duration(s.MedianDuration) * time.Second).String(),
+			(time.Duration(s.P95Duration) * time.Second).String(),
+			billableToString(s.BillableMinutes),
+			fmt.Sprintf("%d", s.TotalBillableMinutes),
+		}
+
+		td = append(td, data)
+
+		if csvPath != "" {
+			runsReport.AddData(data)
+		}
+	}
+
+	if !silent {
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(td).Render()
+		fmt.Println("")
+
+		ttd := pterm.TableData{{"owner", "repo", "workflow", "total_billable_minutes"}}
+		for _, s := range res.Top {
+			ttd = append(ttd, []string{s.Owner, s.Repo, s.Workflow, fmt.Sprintf("%d", s.TotalBillableMinutes)})
+		}
+
+		fmt.Printf("Top %s most expensive workflows\n\n", utils.Bold(len(res.Top)))
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(ttd).Render()
+	}
+
+	if csvPath != "" {
+		runsReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdRunsReport, res)
+	}
+
+	return err
+}
+
+// runsPeriod parses the `--from` and `--to` dates, defaulting to the 30 days before now
+func runsPeriod(from, to string, now time.Time) (time.Time, time.Time, error) {
+	end := now.Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, -30)
+
+	var err error
+
+	if to != "" {
+		if end, err = time.Parse("2006-01-02", to); err != nil {
+			return start, end, fmt.Errorf("invalid --to date %q, must be YYYY-MM-DD", to)
+		}
+	}
+
+	if from != "" {
+		if start, err = time.Parse("2006-01-02", from); err != nil {
+			return start, end, fmt.Errorf("invalid --from date %q, must be YYYY-MM-DD", from)
+		}
+	}
+
+	if start.After(end) {
+		return start, end, fmt.Errorf("--from date must be before --to date")
+	}
+
+	return start, end, nil
+}
+
+// fetchWorkflowRuns returns the workflow runs of a repository created in the period,
+// and the jobs of every run by run ID
+func fetchWorkflowRuns(nameWithOwner string, from, to time.Time) ([]WorkflowRunData, map[int64][]WorkflowJobData, error) {
+	var runs []WorkflowRunData
+	jobs := map[int64][]WorkflowJobData{}
+
+	created := fmt.Sprintf("%s..%s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	for page := 1; ; page++ {
+		sp.Suffix = fmt.Sprintf(
+			" fetching runs report %s %s",
+			utils.Cyan(nameWithOwner),
+			utils.HiBlack(fmt.Sprintf("(page %d)", page)),
+		)
+
+		var res struct {
+			TotalCount   int               `json:"total_count"`
+			WorkflowRuns []WorkflowRunData `json:"workflow_runs"`
+		}
+
+		if err := restClient.Get(
+			fmt.Sprintf("repos/%s/actions/runs?created=%s&per_page=100&page=%d", nameWithOwner, created, page),
+			&res,
+		); err != nil {
+			// Actions disabled or repository not accessible
+			if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+				return runs, jobs, nil
+			}
+
+			return nil, nil, err
+		}
+
+		runs = append(runs, res.WorkflowRuns...)
+
+		if len(res.WorkflowRuns) < 100 || len(runs) >= res.TotalCount {
+			break
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	for i, run := range runs {
+		if run.Status != "completed" {
+			continue
+		}
+
+		sp.Suffix = fmt.Sprintf(
+			" fetching runs report %s %s",
+			utils.Cyan(nameWithOwner),
+			utils.HiBlack(fmt.Sprintf("(jobs of run %d/%d)", i+1, len(runs))),
+		)
+
+		for page := 1; ; page++ {
+			var res struct {
+				TotalCount int               `json:"total_count"`
+				Jobs       []WorkflowJobData `json:"jobs"`
+			}
+
+			if err := restClient.Get(
+				fmt.Sprintf("repos/%s/actions/runs/%d/jobs?per_page=100&page=%d", nameWithOwner, run.ID, page),
+				&res,
+			); err != nil {
+				return nil, nil, err
+			}
+
+			jobs[run.ID] = append(jobs[run.ID], res.Jobs...)
+
+			if len(res.Jobs) < 100 || len(jobs[run.ID]) >= res.TotalCount {
+				break
+			}
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	return runs, jobs, nil
+}
+
+// workflowRunStats aggregates the runs and jobs of a repository per workflow,
+// billable is false for public repositories, which are not billed
+func workflowRunStats(owner, repo string, billable bool, runs []WorkflowRunData, jobs map[int64][]WorkflowJobData) []WorkflowRunStats {
+	var paths []string
+	stats := map[string]*WorkflowRunStats{}
+	durations := map[string][]int{}
+
+	for _, run := range runs {
+		s, ok := stats[run.Path]
+		if !ok {
+			s = &WorkflowRunStats{
+				Owner:           owner,
+				Repo:            repo,
+				Workflow:        run.Name,
+				Path:            run.Path,
+				BillableMinutes: map[string]int{},
+			}
+
+			stats[run.Path] = s
+			paths = append(paths, run.Path)
+		}
+
+		s.Runs++
+
+		switch run.Conclusion {
+		case "success":
+			s.Success++
+		case "failure", "timed_out", "startup_failure":
+			s.Failure++
+		case "cancelled":
+			s.Cancelled++
+		}
+
+		if run.Status == "completed" && !run.RunStartedAt.IsZero() && run.UpdatedAt.After(run.RunStartedAt) {
+			durations[run.Path] = append(durations[run.Path], int(run.UpdatedAt.Sub(run.RunStartedAt).Seconds()))
+		}
+
+		for _, j := range jobs[run.ID] {
+			platform := runnerOS(j.Labels)
+			if !billable || platform == "self-hosted" || j.StartedAt.IsZero() || !j.CompletedAt.After(j.StartedAt) {
+				continue
+			}
+
+			// jobs are billed per started minute
+			m := int(math.Ceil(j.CompletedAt.Sub(j.StartedAt).Minutes()))
+
+			s.BillableMinutes[platform] += m
+			s.TotalBillableMinutes += m
+		}
+	}
+
+	sort.Strings(paths)
+
+	var res []WorkflowRunStats
+	for _, p := range paths {
+		s := stats[p]
+
+		s.SuccessRate = percentage(s.Success, s.Runs)
+		s.FailureRate = percentage(s.Failure, s.Runs)
+		s.CancelRate = percentage(s.Cancelled, s.Runs)
+		s.MedianDuration = percentile(durations[p], 50)
+		s.P95Duration = percentile(durations[p], 95)
+
+		res = append(res, *s)
+	}
+
+	return res
+}
+
+// runnerOS returns the operating system of the GitHub-hosted runner a job ran on, from its labels,
+// or unknown when no label names it
+func runnerOS(labels []string) string {
+	for _, l := range labels {
+		if strings.EqualFold(l, "self-hosted") {
+			return "self-hosted"
+		}
+	}
+
+	for _, l := range labels {
+		l = strings.ToLower(l)
+
+		switch {
+		case strings.Contains(l, "windows"):
+			return "windows"
+		case strings.Contains(l, "macos"):
+			return "macos"
+		case strings.Contains(l, "ubuntu"), strings.Contains(l, "linux"):
+			return "linux"
+		}
+	}
+
+	// larger runners with custom labels do not name their operating system
+	return "unknown"
+}
+
+// percentile returns the p-th percentile of values, using the nearest-rank method
+func percentile(values []int, p int) int {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]int{}, values...)
+	sort.Ints(sorted)
+
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+func percentage(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(n) / float64(total) * 100
+}
+
+// topWorkflowRuns returns the n workflows with the most billable minutes
+func topWorkflowRuns(stats []WorkflowRunStats, n int) []WorkflowRunStats {
+	top := append([]WorkflowRunStats{}, stats...)
+
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].TotalBillableMinutes > top[j].TotalBillableMinutes
+	})
+
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+
+	return top
+}
+
+func billableToString(m map[string]int) string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var s []string
+	for _, k := range keys {
+		s = append(s, fmt.Sprintf("%s: %d", k, m[k]))
+	}
+
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Runs(t *testing.T) {
+	t.Skip()
+}
+
+func Test_runsPeriod(t *testing.T) {
+	now := time.Date(2025, 3, 31, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "2025-03-01..2025-03-31"},
+		{name: "from", from: "2025-03-15", want: "2025-03-15..2025-03-31"},
+		{name: "from and to", from: "2025-01-01", to: "2025-01-31", want: "2025-01-01..2025-01-31"},
+		{name: "invalid", from: "2025-1-1", wantErr: true},
+		{name: "reversed", from: "2025-02-01", to: "2025-01-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := runsPeriod(tt.from, tt.to, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %t, got %v", tt.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got := from.Format("2006-01-02") + ".." + to.Format("2006-01-02"); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_workflowRunStats(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	runs := []WorkflowRunData{
+		{ID: 1, Name: "CI", Path: ".github/workflows/ci.yml", Status: "completed", Conclusion: "success", RunStartedAt: start, UpdatedAt: start.Add(2 * time.Minute)},
+		{ID: 2, Name: "CI", Path: ".github/workflows/ci.yml", Status: "completed", Conclusion: "failure", RunStartedAt: start, UpdatedAt: start.Add(4 * time.Minute)},
+		{ID: 3, Name: "CI", Path: ".github/workflows/ci.yml", Status: "completed", Conclusion: "cancelled", RunStartedAt: start, UpdatedAt: start.Add(10 * time.Minute)},
+		{ID: 4, Name: "CI", Path: ".github/workflows/ci.yml", Status: "in_progress", RunStartedAt: start},
+		{ID: 5, Name: "Release", Path: ".github/workflows/release.yml", Status: "completed", Conclusion: "success", RunStartedAt: start, UpdatedAt: start.Add(time.Minute)},
+	}
+
+	jobs := map[int64][]WorkflowJobData{
+		1: {
+			{StartedAt: start, CompletedAt: start.Add(61 * time.Second), Labels: []string{"ubuntu-latest"}},
+			{StartedAt: start, CompletedAt: start.Add(30 * time.Second), Labels: []string{"windows-latest"}},
+		},
+		2: {
+			{StartedAt: start, CompletedAt: start.Add(3 * time.Minute), Labels: []string{"self-hosted", "linux"}},
+		},
+		5: {
+			{StartedAt: start, CompletedAt: start.Add(5 * time.Minute), Labels: []string{"macos-14"}},
+		},
+	}
+
+	got := workflowRunStats("octo-org", "octo-repo", true, runs, jobs)
+
+	want := []WorkflowRunStats{
+		{
+			Owner:                "octo-org",
+			Repo:                 "octo-repo",
+			Workflow:             "CI",
+			Path:                 ".github/workflows/ci.yml",
+			Runs:                 4,
+			Success:              1,
+			Failure:              1,
+			Cancelled:            1,
+			SuccessRate:          25,
+			FailureRate:          25,
+			CancelRate:           25,
+			MedianDuration:       240,
+			P95Duration:          600,
+			BillableMinutes:      map[string]int{"linux": 2, "windows": 1},
+			TotalBillableMinutes: 3,
+		},
+		{
+			Owner:                "octo-org",
+			Repo:                 "octo-repo",
+			Workflow:             "Release",
+			Path:                 ".github/workflows/release.yml",
+			Runs:                 1,
+			Success:              1,
+			SuccessRate:          100,
+			MedianDuration:       60,
+			P95Duration:          60,
+			BillableMinutes:      map[string]int{"macos": 5},
+			TotalBillableMinutes: 5,
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	top := topWorkflowRuns(got, 1)
+	if len(top) != 1 || top[0].Workflow != "Release" {
+		t.Errorf("Expected Release as the most expensive workflow, got %+v", top)
+	}
+}
+
+func Test_workflowRunStats_Public(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	runs := []WorkflowRunData{
+		{ID: 1, Name: "CI", Path: ".github/workflows/ci.yml", Status: "completed", Conclusion: "success", RunStartedAt: start, UpdatedAt: start.Add(2 * time.Minute)},
+	}
+	jobs := map[int64][]WorkflowJobData{
+		1: {{StartedAt: start, CompletedAt: start.Add(5 * time.Minute), Labels: []string{"macos-14"}}},
+	}
+
+	// public repositories are not billed
+	got := workflowRunStats("octo-org", "octo-repo", false, runs, jobs)
+	if len(got) != 1 || got[0].TotalBillableMinutes != 0 || len(got[0].BillableMinutes) != 0 {
+		t.Errorf("Expected no billable minutes, got %+v", got)
+	}
+}
+
+func Test_runnerOS(t *testing.T) {
+	tests := []struct {
+		labels []string
+		want   string
+	}{
+		{[]string{"ubuntu-latest"}, "linux"},
+		{[]string{"ubuntu-24.04-arm"}, "linux"},
+		{[]string{"windows-2022"}, "windows"},
+		{[]string{"macos-latest"}, "macos"},
+		{[]string{"self-hosted", "windows"}, "self-hosted"},
+		{[]string{"windows-latest-8-cores"}, "windows"},
+		{[]string{"octo-org-linux-16core"}, "linux"},
+		{[]string{"gpu-runner"}, "unknown"},
+		{nil, "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := runnerOS(tt.labels); got != tt.want {
+			t.Errorf("Expected %s for %v, got %s", tt.want, tt.labels, got)
+		}
+	}
+}
+
+func Test_percentile(t *testing.T) {
+	values := []int{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+
+	if got := percentile(values, 50); got != 5 {
+		t.Errorf("Expected median 5, got %d", got)
+	}
+
+	if got := percentile(values, 95); got != 10 {
+		t.Errorf("Expected p95 10, got %d", got)
+	}
+
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("Expected 0, got %d", got)
+	}
+}
@@ -0,0 +1,392 @@
+/*
+Copyright © 2023 Stefan Stölzle <stefan@stoelzle.me>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/stoe/gh-report/internal/utils"
+)
+
+var (
+	SecretsCmd = &cobra.Command{
+		Use:   "secrets",
+		Short: "Report on Actions, Dependabot and Codespaces secrets and variables",
+		Long: heredoc.Docf(
+			`Report on the metadata of Actions, Dependabot and Codespaces secrets and variables, secret values are never read, requires %[1]sadmin:org%[1]s and/or %[1]srepo%[1]s scope`,
+			"`",
+		),
+		RunE: GetSecrets,
+	}
+
+	maxAge int
+
+	// secretsTypes are the products and kinds of secrets and variables, with their endpoint path
+	secretsTypes = []struct {
+		Product string
+		Kind    string
+		Path    string
+	}{
+		{"actions", "secret", "actions/secrets"},
+		{"actions", "variable", "actions/variables"},
+		{"dependabot", "secret", "dependabot/secrets"},
+		{"codespaces", "secret", "codespaces/secrets"},
+	}
+
+	secretsReport utils.CSVReport
+
+	mdSecretsReport = `# GitHub Secrets Report
+
+| Level | Owner | Repo | Environment | Product | Kind | Name | Visibility | Selected Repositories | Updated At | Age (days) | Warnings |
+| ----- | ----- | ---- | ----------- | ------- | ---- | ---- | ---------- | --------------------: | ---------- | ---------: | -------- |
+{{ range . }}| {{ .Level }} | {{ .Owner }} | {{ .Repo }} | {{ .Environment }} | {{ .Product }} | {{ .Kind }} | ` + "`" + `{{ .Name }}` + "`" + ` | {{ .Visibility }} | {{ if eq .Visibility "selected" }}{{ .SelectedRepositories }}{{ end }} | {{ .UpdatedAt.UTC.Format "2006-01-02 15:04:05 MST" }} | {{ .Age }} | {{ range $i, $v := .Warnings }}{{ if $i }}<br/>{{ end }}:warning: {{ $v }}{{ end }} |
+{{ end }}
+`
+)
+
+type (
+	SecretData struct {
+		Name       string    `json:"name"`
+		Visibility string    `json:"visibility"`
+		CreatedAt  time.Time `json:"created_at"`
+		UpdatedAt  time.Time `json:"updated_at"`
+	}
+
+	SecretMetadata struct {
+		Level                string    `json:"level"`
+		Owner                string    `json:"owner"`
+		Repo                 string    `json:"repo,omitempty"`
+		Environment          string    `json:"environment,omitempty"`
+		Product              string    `json:"product"`
+		Kind                 string    `json:"kind"`
+		Name                 string    `json:"name"`
+		Visibility           string    `json:"visibility,omitempty"`
+		SelectedRepositories int       `json:"selected_repositories,omitempty"`
+		CreatedAt            time.Time `json:"created_at"`
+		UpdatedAt            time.Time `json:"updated_at"`
+		Age                  int       `json:"age_days"`
+		Warnings             []string  `json:"warnings,omitempty"`
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(SecretsCmd)
+
+	SecretsCmd.Flags().IntVar(&maxAge, "max-age", 90, "Flag secrets not updated for more than this number of days for rotation")
+}
+
+// GetSecrets returns the metadata of the secrets and variables of organizations and repositories
+func GetSecrets(cmd *cobra.Command, args []string) (err error) {
+	sp.Start()
+
+	var res []SecretMetadata
+	now := time.Now().UTC()
+
+	var targets []RESTRepository
+
+	if repo != "" {
+		targets = append(targets, RESTRepository{Name: repo, FullName: fmt.Sprintf("%s/%s", owner, repo)})
+	} else {
+		orgs, err := listOrganizations()
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+		organizations = append(organizations, orgs...)
+
+		for _, org := range organizations {
+			sp.Suffix = fmt.Sprintf(
+				" fetching secrets report %s",
+				utils.Cyan(org.Login),
+			)
+
+			isUser := org.Login == owner && user.Type == "User"
+
+			// user accounts have no organization secrets
+			if !isUser {
+				for _, t := range secretsTypes {
+					secrets, err := fetchSecrets(fmt.Sprintf("orgs/%s/%s", org.Login, t.Path), t.Kind)
+					if err != nil {
+						sp.Stop()
+						return err
+					}
+
+					for _, s := range secrets {
+						var selected int
+
+						if s.Visibility == "selected" {
+							if selected, err = fetchSelectedRepositories(
+								fmt.Sprintf("orgs/%s/%s/%s/repositories", org.Login, t.Path, url.PathEscape(s.Name)),
+							); err != nil {
+								sp.Stop()
+								return err
+							}
+						}
+
+						m := newSecretMetadata("organization", org.Login, "", "", t.Product, t.Kind, s, now)
+						m.SelectedRepositories = selected
+
+						res = append(res, m)
+					}
+				}
+			}
+
+			repos, err := listRepositories(org.Login, isUser)
+			if err != nil {
+				sp.Stop()
+				return err
+			}
+
+			targets = append(targets, repos...)
+		}
+	}
+
+	for _, r := range targets {
+		if r.Archived {
+			continue
+		}
+
+		sp.Suffix = fmt.Sprintf(
+			" fetching secrets report %s",
+			utils.Cyan(r.FullName),
+		)
+
+		o, n, _ := strings.Cut(r.FullName, "/")
+
+		for _, t := range secretsTypes {
+			secrets, err := fetchSecrets(fmt.Sprintf("repos/%s/%s", r.FullName, t.Path), t.Kind)
+			if err != nil {
+				sp.Stop()
+				return err
+			}
+
+			for _, s := range secrets {
+				res = append(res, newSecretMetadata("repository", o, n, "", t.Product, t.Kind, s, now))
+			}
+		}
+
+		environments, err := fetchEnvironmentNames(r.FullName)
+		if err != nil {
+			sp.Stop()
+			return err
+		}
+
+		for _, env := range environments {
+			for _, kind := range []string{"secret", "variable"} {
+				secrets, err := fetchSecrets(
+					fmt.Sprintf("repos/%s/environments/%s/%ss", r.FullName, url.PathEscape(env), kind),
+					kind,
+				)
+				if err != nil {
+					sp.Stop()
+					return err
+				}
+
+				for _, s := range secrets {
+					res = append(res, newSecretMetadata("environment", o, n, env, "actions", kind, s, now))
+				}
+			}
+		}
+
+		// sleep for 1 second to avoid rate limiting
+		time.Sleep(1 * time.Second)
+	}
+
+	sp.Stop()
+
+	header := []string{
+		"level",
+		"owner",
+		"repo",
+		"environment",
+		"product",
+		"kind",
+		"name",
+		"visibility",
+		"selected_repositories",
+		"updated_at",
+		"age_days",
+		"warnings",
+	}
+
+	var td = pterm.TableData{header}
+
+	// start CSV file
+	if csvPath != "" {
+		secretsReport, err = utils.NewCSVReport(csvPath)
+
+		if err != nil {
+			return err
+		}
+
+		secretsReport.SetHeader(header)
+	}
+
+	for _, s := range res {
+		var selected string
+		if s.Visibility == "selected" {
+			selected = fmt.Sprintf("%d", s.SelectedRepositories)
+		}
+
+		data := []string{
+			s.Level,
+			s.Owner,
+			s.Repo,
+			s.Environment,
+			s.Product,
+			s.Kind,
+			s.Name,
+			s.Visibility,
+			selected,
+			s.UpdatedAt.UTC().Format("2006-01-02 15:04:05 MST"),
+			fmt.Sprintf("%d", s.Age),
+			strings.Join(s.Warnings, ", "),
+		}
+
+		td = append(td, data)
+
+		if csvPath != "" {
+			secretsReport.AddData(data)
+		}
+	}
+
+	if !silent {
+		pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(td).Render()
+	}
+
+	if csvPath != "" {
+		secretsReport.Save()
+	}
+
+	if jsonPath != "" {
+		err = utils.SaveJsonReport(jsonPath, res)
+	}
+
+	if mdPath != "" {
+		err = utils.SaveMDReport(mdPath, mdSecretsReport, res)
+	}
+
+	return err
+}
+
+// fetchSecrets returns the metadata of the secrets or variables at endpoint.
+// Values are never part of the result, variables are decoded into SecretData which has no value field.
+func fetchSecrets(endpoint, kind string) ([]SecretData, error) {
+	var secrets []SecretData
+
+	for page := 1; ; page++ {
+		// secrets and variables are listed under a `secrets` or `variables` key
+		var res struct {
+			TotalCount int          `json:"total_count"`
+			Secrets    []SecretData `json:"secrets"`
+			Variables  []SecretData `json:"variables"`
+		}
+
+		if err := restClient.Get(fmt.Sprintf("%s?per_page=30&page=%d", endpoint, page), &res); err != nil {
+			// not accessible, or the product is not enabled
+			if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+				return secrets, nil
+			}
+
+			return nil, err
+		}
+
+		items := res.Secrets
+		if kind == "variable" {
+			items = res.Variables
+		}
+
+		secrets = append(secrets, items...)
+
+		if len(items) < 30 || len(secrets) >= res.TotalCount {
+			break
+		}
+	}
+
+	return secrets, nil
+}
+
+// fetchSelectedRepositories returns the number of repositories an organization secret or variable is shared with
+func fetchSelectedRepositories(endpoint string) (int, error) {
+	var res struct {
+		TotalCount int `json:"total_count"`
+	}
+
+	if err := restClient.Get(endpoint+"?per_page=1", &res); err != nil {
+		if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "404") {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return res.TotalCount, nil
+}
+
+// fetchEnvironmentNames returns the names of the deployment environments of a repository
+func fetchEnvironmentNames(nameWithOwner string) ([]string, error) {
+	environments, err := fetchEnvironments(nameWithOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range environments {
+		names = append(names, e.Name)
+	}
+
+	return names, nil
+}
+
+// newSecretMetadata returns the report entry of a secret or variable, with warnings for
+// secrets due for rotation and organization secrets visible to all repositories
+func newSecretMetadata(level, owner, repo, env, product, kind string, s SecretData, now time.Time) SecretMetadata {
+	m := SecretMetadata{
+		Level:       level,
+		Owner:       owner,
+		Repo:        repo,
+		Environment: env,
+		Product:     product,
+		Kind:        kind,
+		Name:        s.Name,
+		Visibility:  s.Visibility,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+		Age:         int(now.Sub(s.UpdatedAt).Hours() / 24),
+	}
+
+	if kind == "secret" && maxAge > 0 && m.Age > maxAge {
+		m.Warnings = append(m.Warnings, fmt.Sprintf("not rotated for more than %d days", maxAge))
+	}
+
+	if level == "organization" && s.Visibility == "all" {
+		m.Warnings = append(m.Warnings, fmt.Sprintf("%s visible to all repositories", kind))
+	}
+
+	return m
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Secrets(t *testing.T) {
+	t.Skip()
+}
+
+func Test_newSecretMetadata(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -120)
+	recent := now.AddDate(0, 0, -10)
+
+	maxAge = 90
+	defer func() { maxAge = 90 }()
+
+	tests := []struct {
+		name   string
+		level  string
+		kind   string
+		secret SecretData
+		age    int
+		want   []string
+	}{
+		{
+			name:   "recent repository secret",
+			level:  "repository",
+			kind:   "secret",
+			secret: SecretData{Name: "TOKEN", UpdatedAt: recent},
+			age:    10,
+		},
+		{
+			name:   "old repository secret",
+			level:  "repository",
+			kind:   "secret",
+			secret: SecretData{Name: "TOKEN", UpdatedAt: old},
+			age:    120,
+			want:   []string{"not rotated for more than 90 days"},
+		},
+		{
+			name:   "old variable",
+			level:  "repository",
+			kind:   "variable",
+			secret: SecretData{Name: "REGION", UpdatedAt: old},
+			age:    120,
+		},
+		{
+			name:   "old organization secret visible to all repositories",
+			level:  "organization",
+			kind:   "secret",
+			secret: SecretData{Name: "NPM_TOKEN", Visibility: "all", UpdatedAt: old},
+			age:    120,
+			want:   []string{"not rotated for more than 90 days", "secret visible to all repositories"},
+		},
+		{
+			name:   "organization secret for selected repositories",
+			level:  "organization",
+			kind:   "secret",
+			secret: SecretData{Name: "NPM_TOKEN", Visibility: "selected", UpdatedAt: recent},
+			age:    10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newSecretMetadata(tt.level, "octo-org", "", "", "actions", tt.kind, tt.secret, now)
+
+			if got.Age != tt.age {
+				t.Errorf("Expected age %d, got %d", tt.age, got.Age)
+			}
+
+			if !reflect.DeepEqual(got.Warnings, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got.Warnings)
+			}
+		})
+	}
+}
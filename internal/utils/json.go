@@ -31,6 +31,11 @@ import (
 )
 
 func SaveJsonReport(p string, data interface{}) (err error) {
+	return saveJSON(p, "JSON", data)
+}
+
+// saveJSON writes data as indented JSON to p, label names the format in messages
+func saveJSON(p, label string, data interface{}) (err error) {
 	if _, err := os.Stat(filepath.Dir(p)); err != nil {
 		return fmt.Errorf("failed to open directory, error: %w", err)
 	}
@@ -48,10 +53,10 @@ func SaveJsonReport(p string, data interface{}) (err error) {
 	encoder.SetEscapeHTML(false)
 
 	if err := encoder.Encode(data); err != nil {
-		return fmt.Errorf("failed to encode JSON, error: %w", err)
+		return fmt.Errorf("failed to encode %s, error: %w", label, err)
 	}
 
-	fmt.Fprintf(color.Output, "%s %s\n", HiBlack("JSON saved to:"), p)
+	fmt.Fprintf(color.Output, "%s %s\n", HiBlack(label+" saved to:"), p)
 
 	return nil
 }
@@ -0,0 +1,9 @@
+package utils
+
+import (
+	"testing"
+)
+
+func Test_SARIF(t *testing.T) {
+	t.Skip()
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_SaveTextReport(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "graph.dot")
+
+	if err := SaveTextReport(p, "DOT", "digraph {}\n"); err != nil {
+		t.Fatalf("SaveTextReport() error = %v", err)
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "digraph {}\n" {
+		t.Errorf("Expected digraph {}, got %s", b)
+	}
+
+	if err := SaveTextReport(filepath.Join(t.TempDir(), "missing", "graph.dot"), "DOT", ""); err == nil {
+		t.Errorf("Expected error for missing directory")
+	}
+}